@@ -0,0 +1,81 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package assert
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// WithAssertionFailure decorates the error with an assertion failure marker.
+// This is not intended to be used directly (see AssertionFailedf() for
+// further decoration).
+//
+// Detail is shown:
+// - when formatting with `%+v`.
+func WithAssertionFailure(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withAssertionFailure{cause: err}
+}
+
+// HasAssertionFailure returns true if the error or any of its causes
+// is an assertion failure annotation.
+func HasAssertionFailure(err error) bool {
+	return !errbase.Walk(err, func(c error) bool { return !IsAssertionFailure(c) })
+}
+
+// IsAssertionFailure returns true if the error (not its causes) is an
+// assertion failure annotation. Consider using HasAssertionFailure
+// to test both the error and its causes.
+func IsAssertionFailure(err error) bool {
+	_, ok := err.(*withAssertionFailure)
+
+	return ok
+}
+
+type withAssertionFailure struct {
+	cause error
+}
+
+var (
+	_ error         = (*withAssertionFailure)(nil)
+	_ fmt.Formatter = (*withAssertionFailure)(nil)
+)
+
+func (w *withAssertionFailure) Error() string { return w.cause.Error() }
+func (w *withAssertionFailure) Cause() error  { return w.cause }
+func (w *withAssertionFailure) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *withAssertionFailure) Format(s fmt.State, verb rune) { errbase.FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *withAssertionFailure) SafeFormatError(p errbase.Printer) error {
+	if p.Detail() {
+		p.Printf("assertion failure")
+	}
+
+	return w.cause
+}
+
+func init() {
+	errbase.RegisterRewrapper((*withAssertionFailure)(nil), func(_, newCause error) error {
+		return &withAssertionFailure{cause: newCause}
+	})
+}
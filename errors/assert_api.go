@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"github.com/StevenACoffman/anotherr/errors/assert"
+	"github.com/StevenACoffman/anotherr/errors/errutil"
+)
+
+// WithAssertionFailure decorates the error with an assertion failure marker.
+// This is not intended to be used directly (see AssertionFailedf() for
+// further decoration).
+//
+// Detail is shown:
+// - when formatting with `%+v`.
+func WithAssertionFailure(err error) error { return assert.WithAssertionFailure(err) }
+
+// HasAssertionFailure returns true if the error or any of its causes
+// is an assertion failure annotation.
+func HasAssertionFailure(err error) bool { return assert.HasAssertionFailure(err) }
+
+// IsAssertionFailure returns true if the error (not its causes) is an
+// assertion failure annotation. Consider using HasAssertionFailure
+// to test both the error and its causes.
+func IsAssertionFailure(err error) bool { return assert.IsAssertionFailure(err) }
+
+// Must panics if err is not nil. This is meant for init or setup code
+// where an error is genuinely impossible; the panic object is an
+// assertion failure wrapping err.
+func Must(err error) {
+	if err != nil {
+		panic(errutil.NewAssertionErrorWithWrappedErrDepthf(1, err, "unexpected error"))
+	}
+}
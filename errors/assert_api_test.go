@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMust(t *testing.T) {
+	Must(nil)
+
+	orig := New("impossible")
+	defer func() {
+		err, ok := recover().(error)
+		if !ok {
+			t.Fatalf("expected Must to panic with an error")
+		}
+		if !HasAssertionFailure(err) {
+			t.Errorf("expected an assertion failure, got %v", err)
+		}
+		if !Is(err, orig) {
+			t.Errorf("expected the original error in the chain, got %v", err)
+		}
+	}()
+	Must(orig)
+}
+
+func TestAssertionFailureSafeDetails(t *testing.T) {
+	details := GetSafeDetails(AssertionFailedf("bad %s", "value"))
+	if len(details) != 1 || !strings.Contains(details[0], "TestAssertionFailureSafeDetails") {
+		t.Errorf("expected only the stack trace, got %q", details)
+	}
+
+	details = GetSafeDetails(NewAssertionErrorWithWrappedErrf(New("boom"), "bad %s", "value"))
+	if !containsString(details, "bad value") {
+		t.Errorf("expected the formatted message, got %q", details)
+	}
+}
@@ -0,0 +1,299 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// The binary encoding produced by MarshalBinary() is, after a version
+// byte, the number of layers in the chain of the error followed by
+// each layer, from the outermost to the innermost. A layer is made of
+// its type, message, kind, code and fields. Integers are encoded as
+// varints, and strings as their length followed by their bytes. The
+// type is the ID of one of the types known to FromJSON(), or 0
+// followed by the name of the type. Each field is its key followed by
+// a tag for the type of its value and the value itself; the fields are
+// sorted by key.
+
+// binaryVersion is the version of the binary encoding.
+const binaryVersion = 1
+
+// binaryTypeNames are the type names with an ID in the binary
+// encoding: the ID of a type is its index in the list, plus one.
+var binaryTypeNames = []string{
+	errorKindTypeName,
+	khanErrorTypeName,
+	withFieldsTypeName,
+	withCodeTypeName,
+}
+
+// The tags of the types of field values in the binary encoding.
+const (
+	binaryNil byte = iota
+	binaryBool
+	binaryInt
+	binaryInt64
+	binaryFloat64
+	binaryString
+	// binaryJSON is for the values of all other types, encoded as JSON.
+	binaryJSON
+)
+
+// MarshalBinary encodes err, and all its causes, to a compact binary
+// format, for high-throughput transports where JSON (see ToJSON) is
+// too costly. The information preserved is the same as with ToJSON():
+// the types, messages, kinds, fields and codes of the layers, but not
+// their stack traces.
+//
+// Field values of type bool, int, int64, float64 and string are
+// decoded to their original type. Other values are encoded as JSON,
+// or replaced by the result of formatting them with %v when they
+// cannot be represented in JSON.
+func MarshalBinary(err error) ([]byte, error) {
+	var layers []*jsonError
+	for je := toJSONError(err); je != nil; je = je.Cause {
+		layers = append(layers, je)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(binaryVersion)
+	writeUvarint(&buf, uint64(len(layers)))
+	for _, je := range layers {
+		writeBinaryType(&buf, je.Type)
+		writeString(&buf, je.Message)
+		writeString(&buf, je.Kind)
+		writeString(&buf, je.Code)
+		writeUvarint(&buf, uint64(len(je.Fields)))
+		// The fields are written in the order of their keys, so that an
+		// error always has the same encoding.
+		keys := make([]string, 0, len(je.Fields))
+		for k := range je.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeString(&buf, k)
+			if err := writeFieldValue(&buf, je.Fields[k]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes an error previously encoded with
+// MarshalBinary. The second return value reports a problem with the
+// decoding itself. As with FromJSON(), Khan errors, fields and codes
+// are decoded to their original types, and other errors to opaque
+// errors that preserve their message and the name of their type. The
+// result is tagged as remote (see IsRemote).
+func UnmarshalBinary(data []byte) (error, error) {
+	r := bytes.NewReader(data)
+	version, err := r.ReadByte()
+	if err != nil {
+		return nil, errBinaryTruncated
+	}
+	if version != binaryVersion {
+		return nil, fmt.Errorf("unsupported binary error encoding version %d", version)
+	}
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, errBinaryTruncated
+	}
+
+	var outer, last *jsonError
+	for i := uint64(0); i < n; i++ {
+		je, err := readBinaryLayer(r)
+		if err != nil {
+			return nil, err
+		}
+		if outer == nil {
+			outer = je
+		} else {
+			last.Cause = je
+		}
+		last = je
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after binary error encoding", r.Len())
+	}
+
+	return markRemote(fromJSONError(outer)), nil
+}
+
+var errBinaryTruncated = fmt.Errorf("truncated binary error encoding")
+
+func readBinaryLayer(r *bytes.Reader) (*jsonError, error) {
+	je := &jsonError{}
+	id, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, errBinaryTruncated
+	}
+	switch {
+	case id == 0:
+		if je.Type, err = readString(r); err != nil {
+			return nil, err
+		}
+	case id <= uint64(len(binaryTypeNames)):
+		je.Type = binaryTypeNames[id-1]
+	default:
+		return nil, fmt.Errorf("unknown type ID %d in binary error encoding", id)
+	}
+	for _, s := range []*string{&je.Message, &je.Kind, &je.Code} {
+		if *s, err = readString(r); err != nil {
+			return nil, err
+		}
+	}
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > uint64(r.Len()) {
+		// Each field takes at least one byte.
+		return nil, errBinaryTruncated
+	}
+	if n > 0 {
+		je.Fields = make(Fields, n)
+	}
+	for i := uint64(0); i < n; i++ {
+		k, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		if je.Fields[k], err = readFieldValue(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return je, nil
+}
+
+func writeBinaryType(buf *bytes.Buffer, typeName string) {
+	for i, name := range binaryTypeNames {
+		if name == typeName {
+			writeUvarint(buf, uint64(i+1))
+
+			return
+		}
+	}
+	writeUvarint(buf, 0)
+	writeString(buf, typeName)
+}
+
+func writeFieldValue(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(binaryNil)
+	case bool:
+		buf.WriteByte(binaryBool)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case int:
+		buf.WriteByte(binaryInt)
+		writeVarint(buf, int64(v))
+	case int64:
+		buf.WriteByte(binaryInt64)
+		writeVarint(buf, v)
+	case float64:
+		buf.WriteByte(binaryFloat64)
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+		buf.Write(b[:])
+	case string:
+		buf.WriteByte(binaryString)
+		writeString(buf, v)
+	default:
+		// toJSONError() already replaced the values that cannot be
+		// encoded to JSON.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(binaryJSON)
+		writeString(buf, string(data))
+	}
+
+	return nil
+}
+
+func readFieldValue(r *bytes.Reader) (interface{}, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, errBinaryTruncated
+	}
+	switch tag {
+	case binaryNil:
+		return nil, nil
+	case binaryBool:
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, errBinaryTruncated
+		}
+
+		return b != 0, nil
+	case binaryInt, binaryInt64:
+		i, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, errBinaryTruncated
+		}
+		if tag == binaryInt {
+			return int(i), nil
+		}
+
+		return i, nil
+	case binaryFloat64:
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, errBinaryTruncated
+		}
+
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), nil
+	case binaryString:
+		return readString(r)
+	case binaryJSON:
+		s, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("unknown field value tag %d in binary error encoding", tag)
+}
+
+func writeUvarint(buf *bytes.Buffer, x uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], x)])
+}
+
+func writeVarint(buf *bytes.Buffer, x int64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutVarint(b[:], x)])
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	writeUvarint(buf, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > uint64(r.Len()) {
+		return "", errBinaryTruncated
+	}
+	b := make([]byte, n)
+	_, _ = r.Read(b)
+
+	return string(b), nil
+}
@@ -0,0 +1,91 @@
+package errors
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	err := Wrap(WrapWithFields(NotFound("no user"), Fields{
+		"user":    42,
+		"name":    "bob",
+		"admin":   false,
+		"score":   1.5,
+		"big":     int64(1) << 40,
+		"tags":    []string{"a", "b"},
+		"nothing": nil,
+	}), "loading")
+
+	data, encErr := MarshalBinary(err)
+	if encErr != nil {
+		t.Fatal(encErr)
+	}
+	decoded, decErr := UnmarshalBinary(data)
+	if decErr != nil {
+		t.Fatal(decErr)
+	}
+
+	if decoded.Error() != err.Error() {
+		t.Errorf("expected message %q, got %q", err.Error(), decoded.Error())
+	}
+	if kind := GetKind(decoded); kind != NotFoundKind {
+		t.Errorf("expected kind %v, got %v", NotFoundKind, kind)
+	}
+	if !IsRemote(decoded) {
+		t.Errorf("expected the decoded error to be remote")
+	}
+	fields := GetFields(decoded)
+	for k, want := range map[string]interface{}{
+		"user": 42, "name": "bob", "admin": false, "score": 1.5, "big": int64(1) << 40,
+	} {
+		if got := fields[k]; got != want {
+			t.Errorf("field %s: expected %#v, got %#v", k, want, got)
+		}
+	}
+}
+
+func TestBinaryDeterministic(t *testing.T) {
+	fields := Fields{}
+	for _, k := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		fields[k] = k
+	}
+	err := WrapWithFields(New("x"), fields)
+
+	first, _ := MarshalBinary(err)
+	for i := 0; i < 20; i++ {
+		if data, _ := MarshalBinary(err); !bytes.Equal(data, first) {
+			t.Fatalf("the encoding changed between calls")
+		}
+	}
+}
+
+func TestBinaryOpaqueLeaf(t *testing.T) {
+	err := Wrap(&customError{"custom"}, "ctx")
+
+	data, _ := MarshalBinary(err)
+	decoded, decErr := UnmarshalBinary(data)
+	if decErr != nil {
+		t.Fatal(decErr)
+	}
+	if decoded.Error() != "ctx: custom" {
+		t.Errorf("expected the message to be preserved, got %q", decoded.Error())
+	}
+}
+
+type customError struct{ msg string }
+
+func (e *customError) Error() string { return e.msg }
+
+func BenchmarkMarshal(b *testing.B) {
+	err := Wrap(WrapWithFields(NotFound("no user"), Fields{"user": 42, "name": "bob"}), "loading")
+	b.Run("binary", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = MarshalBinary(err)
+		}
+	})
+	b.Run("json", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = ToJSON(err)
+		}
+	})
+}
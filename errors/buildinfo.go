@@ -0,0 +1,38 @@
+package errors
+
+import "runtime/debug"
+
+// The fields holding the build information recorded by
+// WithBuildInfo.
+const (
+	buildVersionField  = "build_version"
+	buildRevisionField = "build_revision"
+)
+
+// readBuildInfo is debug.ReadBuildInfo, as a variable for tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// WithBuildInfo records the version of the main module of the binary
+// and its VCS revision, when known, as the fields "build_version" and
+// "build_revision", in a new layer around err. The fields are
+// preserved by ToJSON() and MarshalBinary(), so that the receiver of
+// an error can tell which build of the sender produced it.
+// If err is nil, WithBuildInfo returns nil. If the binary has no
+// build information, err is returned unchanged.
+func WithBuildInfo(err error) error {
+	if err == nil {
+		return nil
+	}
+	info, ok := readBuildInfo()
+	if !ok {
+		return err
+	}
+	fields := Fields{buildVersionField: info.Main.Version}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			fields[buildRevisionField] = s.Value
+		}
+	}
+
+	return WrapWithFieldsAndDepth(err, fields, 1)
+}
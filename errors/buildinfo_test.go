@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestWithBuildInfo(t *testing.T) {
+	defer func(saved func() (*debug.BuildInfo, bool)) { readBuildInfo = saved }(readBuildInfo)
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main:     debug.Module{Path: "example.com/app", Version: "v1.2.3"},
+			Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "abc123"}},
+		}, true
+	}
+
+	err := WithBuildInfo(New("boom"))
+	data, encErr := ToJSON(err)
+	if encErr != nil {
+		t.Fatal(encErr)
+	}
+	fromJSON, decErr := FromJSON(data)
+	if decErr != nil {
+		t.Fatal(decErr)
+	}
+	data, encErr = MarshalBinary(err)
+	if encErr != nil {
+		t.Fatal(encErr)
+	}
+	fromBinary, decErr := UnmarshalBinary(data)
+	if decErr != nil {
+		t.Fatal(decErr)
+	}
+
+	for name, e := range map[string]error{"local": err, "json": fromJSON, "binary": fromBinary} {
+		if v, _ := GetField(e, buildVersionField); v != "v1.2.3" {
+			t.Errorf("%s: expected version %q, got %v", name, "v1.2.3", v)
+		}
+		if v, _ := GetField(e, buildRevisionField); v != "abc123" {
+			t.Errorf("%s: expected revision %q, got %v", name, "abc123", v)
+		}
+	}
+
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	if root := New("boom"); WithBuildInfo(root) != root {
+		t.Error("expected the error unchanged without build information")
+	}
+	if WithBuildInfo(nil) != nil {
+		t.Error("expected nil")
+	}
+}
@@ -0,0 +1,37 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// categoryField is the field holding the category set by WithCategory.
+const categoryField = "category"
+
+// WithCategory records a category for err, as a field in a new layer
+// around err: a free-form grouping dimension, orthogonal to the kind,
+// that names the subsystem where the failure was detected, e.g.
+// "database", "network" or "serialization". This lets dashboards group
+// errors finely even when their kinds are coarse. If err is nil,
+// WithCategory returns nil.
+func WithCategory(err error, category string) error {
+	if err == nil {
+		return nil
+	}
+
+	return WrapWithFieldsAndDepth(err, Fields{categoryField: category}, 1)
+}
+
+// GetCategory returns the category recorded by WithCategory on err,
+// or on any of its causes, or "" if there is none. When there are
+// several, the innermost wins: it was set closest to where the error
+// was detected.
+func GetCategory(err error) string {
+	var res string
+	errbase.Walk(err, func(c error) bool {
+		if category, ok := layerFields(c)[categoryField].(string); ok {
+			res = category
+		}
+
+		return true
+	})
+
+	return res
+}
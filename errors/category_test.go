@@ -0,0 +1,23 @@
+package errors
+
+import "testing"
+
+func TestGetCategory(t *testing.T) {
+	err := WithCategory(New("connection reset"), "network")
+	err = Wrap(err, "querying users")
+	if got := GetCategory(err); got != "network" {
+		t.Errorf("expected %q through wrapping, got %q", "network", got)
+	}
+
+	err = WithCategory(err, "database")
+	if got := GetCategory(err); got != "network" {
+		t.Errorf("expected the innermost category %q, got %q", "network", got)
+	}
+
+	if got := GetCategory(New("boom")); got != "" {
+		t.Errorf("expected no category, got %q", got)
+	}
+	if WithCategory(nil, "network") != nil {
+		t.Error("expected nil")
+	}
+}
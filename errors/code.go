@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// WithCode annotates err with a machine-readable code, such as the
+// string codes defined by an API (e.g. "USER_ALREADY_EXISTS"). This
+// complements kinds, which are shared by all our services, with
+// codes specific to an application. The code is considered safe for
+// reporting, and is preserved by ToJSON() and FromJSON().
+// If err is nil, WithCode returns nil.
+//
+// Detail is shown:
+// - via `errors.GetSafeDetails()`.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func WithCode(err error, code string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withCode{cause: err, code: code}
+}
+
+// GetCode returns the code attached to err with WithCode(). If several
+// codes were attached in the chain of err, the outermost one wins, so
+// that a caller can translate the codes of the errors it receives.
+func GetCode(err error) (string, bool) {
+	var code string
+	found := false
+	errbase.Walk(err, func(c error) bool {
+		if w, ok := c.(*withCode); ok {
+			code, found = w.code, true
+		}
+
+		return !found
+	})
+
+	return code, found
+}
+
+// withCode is an error wrapper that carries a machine-readable code.
+type withCode struct {
+	cause error
+	code  string
+}
+
+var (
+	_ error         = (*withCode)(nil)
+	_ fmt.Formatter = (*withCode)(nil)
+)
+
+func (w *withCode) Error() string { return w.cause.Error() }
+func (w *withCode) Cause() error  { return w.cause }
+func (w *withCode) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *withCode) Format(s fmt.State, verb rune) { errbase.FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *withCode) SafeFormatError(p errbase.Printer) (next error) {
+	if p.Detail() {
+		p.Printf("code: %s", w.code)
+	}
+
+	return w.cause
+}
+
+// SafeDetails implements the errbase.SafeDetailer interface.
+func (w *withCode) SafeDetails() []string {
+	return []string{w.code}
+}
+
+func init() {
+	errbase.RegisterRewrapper((*withCode)(nil), func(err, newCause error) error {
+		return &withCode{cause: newCause, code: err.(*withCode).code}
+	})
+}
@@ -0,0 +1,26 @@
+package errors
+
+import "testing"
+
+func TestGetCode(t *testing.T) {
+	err := Wrap(WithCode(WithCode(New("duplicate"), "DB_UNIQUE"), "USER_ALREADY_EXISTS"), "signing up")
+	if code, ok := GetCode(err); !ok || code != "USER_ALREADY_EXISTS" {
+		t.Errorf("expected the outermost code, got %q, %v", code, ok)
+	}
+
+	data, jsonErr := ToJSON(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	decoded, jsonErr := FromJSON(data)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if code, ok := GetCode(decoded); !ok || code != "USER_ALREADY_EXISTS" {
+		t.Errorf("expected the code to survive the round trip, got %q, %v", code, ok)
+	}
+
+	if _, ok := GetCode(New("x")); ok {
+		t.Errorf("expected no code")
+	}
+}
@@ -0,0 +1,67 @@
+package errors
+
+// kindSeverities lists the error kinds from the most severe to the
+// least severe. Kinds that are not listed, including UnspecifiedKind,
+// are less severe than all the listed ones.
+//
+// Roughly: failures of our own code come first, then failures of
+// the services we depend on, then problems with the request itself.
+var kindSeverities = []errorKind{
+	InternalKind,
+	NotImplementedKind,
+	ServiceKind,
+	KhanServiceKind,
+	TransientServiceKind,
+	TransientKhanServiceKind,
+	GraphqlResponseKind,
+	UnauthorizedKind,
+	NotAllowedKind,
+	InvalidInputKind,
+	NotFoundKind,
+}
+
+// kindSeverity returns the rank of kind in kindSeverities. Higher is
+// more severe.
+func kindSeverity(kind errorKind) int {
+	for i, k := range kindSeverities {
+		if k == kind {
+			return len(kindSeverities) - i
+		}
+	}
+
+	return 0
+}
+
+// CombineWithKind attaches secondary to primary as a secondary error
+// (see WithSecondaryError), and gives the result the most severe of
+// the kinds of both errors, as reported by GetKind. From most to
+// least severe, the kinds are ordered:
+//
+//	Internal > NotImplemented
+//	  > Service > KhanService > TransientService > TransientKhanService
+//	  > GraphqlResponse > Unauthorized > NotAllowed > InvalidInput
+//	  > NotFound > Unspecified
+//
+// For example, combining a NotFound error with an Internal error
+// results in an Internal error, whichever of the two is primary.
+// The message of the result is that of primary.
+//
+// If either error is nil, the other one is returned as-is.
+func CombineWithKind(primary, secondary error) error {
+	if primary == nil {
+		return secondary
+	}
+	if secondary == nil {
+		return primary
+	}
+	kind := GetKind(primary)
+	if secondaryKind := GetKind(secondary); kindSeverity(secondaryKind) > kindSeverity(kind) {
+		kind = secondaryKind
+	}
+	err := WithSecondaryError(primary, secondary)
+	if kind == GetKind(primary) {
+		return err
+	}
+
+	return khanWrapWithFieldsAndDepth(kind, err, nil, 1)
+}
@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCombineWithKind(t *testing.T) {
+	notFound := NotFound(New("no user"))
+	internal := Internal(New("database down"))
+
+	for _, tc := range []struct {
+		name               string
+		primary, secondary error
+	}{
+		{"NotFound first", notFound, internal},
+		{"Internal first", internal, notFound},
+	} {
+		err := CombineWithKind(tc.primary, tc.secondary)
+		if kind := GetKind(err); kind != InternalKind {
+			t.Errorf("%s: expected kind %v, got %v", tc.name, InternalKind, kind)
+		}
+		if got, want := err.Error(), tc.primary.Error(); got != want {
+			t.Errorf("%s: expected the message of the primary %q, got %q", tc.name, want, got)
+		}
+		if verbose := fmt.Sprintf("%+v", err); !strings.Contains(verbose, tc.secondary.Error()) {
+			t.Errorf("%s: expected the secondary error in %%+v, got:\n%s", tc.name, verbose)
+		}
+	}
+}
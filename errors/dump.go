@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// dumpStackFrames is the number of stack frames printed per layer by
+// Dump().
+const dumpStackFrames = 5
+
+// Dump renders err for debugging, e.g. in the output of `go test -v`:
+// the message, then a section per layer, from the outermost to the
+// innermost, with the Go type of the layer, the message prefix it
+// contributes, its kind, its own fields and the top of its stack
+// trace:
+//
+//	error: loading user: not found
+//	=== (1) *withstack.withStack
+//	  stack:
+//	    main.loadUser
+//	    	/src/app/user.go:14
+//	=== (2) *errutil.withPrefix
+//	  prefix: loading user
+//	=== (3) *errors.khanError
+//	  kind: not found
+//	  fields: [user:42]
+//	  ...
+//
+// Unlike %+v, which merges the layers into entries and elides the
+// repeated stack frames, each layer is shown on its own. The layers
+// are visited as with errbase.Walk(): the section of a multi-error
+// gives its number of branches, and is followed by the sections of the
+// layers of each branch in turn. Returns "" if err is nil.
+func Dump(err error) string {
+	if err == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "error: %s\n", err.Error())
+	n := 0
+	errbase.Walk(err, func(c error) bool {
+		n++
+		fmt.Fprintf(&b, "=== (%d) %s\n", n, errbase.TypeName(c))
+		if cause := errbase.UnwrapOnce(c); cause != nil {
+			if prefix := errbase.ExtractPrefix(c, cause); prefix != "" {
+				fmt.Fprintf(&b, "  prefix: %s\n", prefix)
+			}
+		} else if m, ok := c.(interface{ Unwrap() []error }); ok {
+			fmt.Fprintf(&b, "  branches: %d\n", len(m.Unwrap()))
+		} else {
+			fmt.Fprintf(&b, "  message: %s\n", c.Error())
+		}
+		switch e := c.(type) {
+		case *khanError:
+			fmt.Fprintf(&b, "  kind: %s\n", e.kind)
+		case errorKind:
+			fmt.Fprintf(&b, "  kind: %s\n", e)
+		}
+		if fields := layerFields(c); len(fields) != 0 {
+			b.WriteString("  fields: [")
+			fieldsIterate(fields, func(i int, s string) {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString(s)
+			})
+			b.WriteString("]\n")
+		}
+		if p, ok := c.(errbase.StackTraceProvider); ok {
+			dumpStackTrace(&b, p.StackTrace())
+		}
+
+		return true
+	})
+
+	return b.String()
+}
+
+// dumpStackTrace prints the first dumpStackFrames frames of st, for
+// Dump().
+func dumpStackTrace(b *strings.Builder, st errbase.StackTrace) {
+	if len(st) == 0 {
+		return
+	}
+	b.WriteString("  stack:\n")
+	for i, f := range st {
+		if i == dumpStackFrames {
+			fmt.Fprintf(b, "    ... (%d more frames)\n", len(st)-i)
+
+			break
+		}
+		fmt.Fprintf(b, "    %s\n", strings.ReplaceAll(fmt.Sprintf("%+v", f), "\n", "\n    "))
+	}
+}
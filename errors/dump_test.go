@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDumpSectionPerLayer(t *testing.T) {
+	err := WrapWithFields(NotFound("no user"), Fields{"user": 42})
+	err = Wrap(err, "loading")
+
+	dump := Dump(err)
+	for _, want := range []string{
+		"error: loading: not found\n",
+		"=== (1) *withstack.withStack\n",
+		"=== (2) *errutil.withPrefix\n  prefix: loading\n",
+		"=== (3) *errors.withFields\n  fields: [user:42]\n",
+		"=== (4) *errors.khanError\n  kind: not found\n  fields: [message:no user]\n",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("expected %q in the dump:\n%s", want, dump)
+		}
+	}
+}
+
+func TestDumpThroughMultiError(t *testing.T) {
+	err := WithHint(multiError{New("a"), New("b")}, "h")
+
+	dump := Dump(err)
+	for _, want := range []string{
+		"=== (2) errors.multiError\n  branches: 2\n",
+		"=== (4) *errutil.leafError\n  message: a\n",
+		"=== (6) *errutil.leafError\n  message: b\n",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("expected %q in the dump:\n%s", want, dump)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package errbase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// maxFormatCacheEntries bounds the size of the format cache. When it
+// is full, the cache is emptied and starts over.
+const maxFormatCacheEntries = 1024
+
+// formatCache memoizes the output of FormatError() when enabled with
+// SetFormatCache().
+var formatCache = struct {
+	sync.Mutex
+	entries map[formatCacheKey]string
+	// generation is the value of registryGeneration when the entries
+	// were rendered.
+	generation uint64
+}{}
+
+// formatCacheEnabled is set when SetFormatCache(true) was called. It
+// is read on every call to FormatError(), hence the atomic: the
+// default path, with the cache disabled, takes no lock.
+var formatCacheEnabled int32
+
+// formatCacheKey identifies a rendering of an error: the error itself
+// (always a pointer, see formatCacheKeyOf), the verb and flags.
+type formatCacheKey struct {
+	err   error
+	verb  rune
+	flags string
+}
+
+// SetFormatCache controls whether FormatError() memoizes its output,
+// for each error and verb. This saves the cost of the recursive
+// formatting when the same error is printed repeatedly, e.g. a %+v
+// logged at every attempt of a retried operation. Since errors are
+// usually formatted once, this is disabled by default.
+//
+// The errors are cached by identity, so only those of pointer types
+// are cached, and they must not change after they are first
+// formatted. The cache holds a bounded number of errors, which it
+// keeps from being garbage collected until it is emptied. It is
+// emptied whenever a setting or registry of this module changes, e.g.
+// with SetStackPathMode() or RegisterRedactionPattern(), since the
+// output may change too. Settings defined elsewhere that affect how
+// some errors format themselves are not tracked: call SetFormatCache()
+// again after changing them, to empty the cache.
+func SetFormatCache(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	formatCache.Lock()
+	defer formatCache.Unlock()
+	atomic.StoreInt32(&formatCacheEnabled, v)
+	formatCache.entries = nil
+}
+
+// formatCacheKeyOf returns the cache key for the rendering of err
+// according to s and verb, if that rendering may be cached.
+func formatCacheKeyOf(err error, s fmt.State, verb rune) (formatCacheKey, bool) {
+	if atomic.LoadInt32(&formatCacheEnabled) == 0 || err == nil || reflect.TypeOf(err).Kind() != reflect.Ptr {
+		return formatCacheKey{}, false
+	}
+	// The width and precision are rare and not worth a key of their
+	// own: such renderings are not cached.
+	if _, ok := s.Width(); ok {
+		return formatCacheKey{}, false
+	}
+	if _, ok := s.Precision(); ok {
+		return formatCacheKey{}, false
+	}
+	var flags []byte
+	for _, f := range []byte("+-# 0") {
+		if s.Flag(int(f)) {
+			flags = append(flags, f)
+		}
+	}
+
+	return formatCacheKey{err: err, verb: verb, flags: string(flags)}, true
+}
+
+func lookupFormatCache(key formatCacheKey) (string, bool) {
+	formatCache.Lock()
+	defer formatCache.Unlock()
+	if formatCache.generation != atomic.LoadUint64(&registryGeneration) {
+		return "", false
+	}
+	out, ok := formatCache.entries[key]
+
+	return out, ok
+}
+
+// storeFormatCache records out as the rendering of key, made when
+// registryGeneration was generation.
+func storeFormatCache(key formatCacheKey, out string, generation uint64) {
+	formatCache.Lock()
+	defer formatCache.Unlock()
+	if atomic.LoadInt32(&formatCacheEnabled) == 0 || generation != atomic.LoadUint64(&registryGeneration) {
+		return
+	}
+	if formatCache.entries == nil || formatCache.generation != generation ||
+		len(formatCache.entries) >= maxFormatCacheEntries {
+		formatCache.entries = make(map[formatCacheKey]string)
+		formatCache.generation = generation
+	}
+	formatCache.entries[key] = out
+}
+
+// formatErrorCached is FormatError() with the format cache.
+func formatErrorCached(err error, s fmt.State, verb rune) {
+	key, ok := formatCacheKeyOf(err, s, verb)
+	if !ok {
+		formatErrorInternal(err, s, verb)
+
+		return
+	}
+	if out, ok := lookupFormatCache(key); ok {
+		io.WriteString(s, out)
+
+		return
+	}
+	generation := atomic.LoadUint64(&registryGeneration)
+	rec := &recordingState{State: s}
+	formatErrorInternal(err, rec, verb)
+	storeFormatCache(key, rec.buf.String(), generation)
+}
+
+// recordingState is a fmt.State that keeps a copy of what is written
+// to it.
+type recordingState struct {
+	fmt.State
+	buf bytes.Buffer
+}
+
+func (r *recordingState) Write(b []byte) (int, error) {
+	r.buf.Write(b)
+
+	return r.State.Write(b)
+}
@@ -28,6 +28,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -47,8 +48,17 @@ import (
 // then recurses on its cause.
 //
 // Otherwise, its Error() text is printed.
+//
+// Messages are not required to be valid UTF-8, and their bytes are
+// passed through unchanged: invalid bytes are neither escaped nor
+// replaced with U+FFFD. So %v and %s print exactly the bytes of
+// Error(), %q quotes them like strconv.Quote() (escaping the invalid
+// bytes as \xNN), and %x/%X print them in hex.
+//
+// See SetFormatCache() to memoize the output for errors formatted
+// repeatedly.
 func FormatError(err error, s fmt.State, verb rune) {
-	formatErrorInternal(err, s, verb)
+	formatErrorCached(err, s, verb)
 }
 
 // Formattable wraps an error into a fmt.Formatter which
@@ -75,11 +85,13 @@ func formatErrorInternal(err error, s fmt.State, verb rune) {
 	// therefore safe to disregard that State may be a specific printer
 	// implementation and use one of our choice instead.
 
-	p := state{State: s}
+	p := state{State: s, opts: getFormatOptions()}
 
 	switch {
-	case verb == 'v' && s.Flag('+') && !s.Flag('#'):
-		// Here we are going to format as per %+v, into p.buf.
+	case (verb == 'v' || verb == 'q') && s.Flag('+') && !s.Flag('#'):
+		// Here we are going to format as per %+v, into p.buf. For %+q,
+		// the result is then quoted as a Go string literal, so that the
+		// detail can be embedded in contexts that expect a single line.
 		//
 		// We need to start with the innermost (root cause) error first,
 		// then the layers of wrapping from innermost to outermost, so as
@@ -131,6 +143,12 @@ func formatErrorInternal(err error, s fmt.State, verb rune) {
 		p.formatSingleLineOutput()
 		p.finishDisplay(verb)
 
+	case verb == 'd':
+		// As a debugging aid, %d (and %+d) print the number of layers
+		// in the chain of causes, to help spot over-wrapping.
+		fmt.Fprintf(&p.finalBuf, "%d", Depth(err))
+		p.finishDisplay('s')
+
 	default:
 		// Unknown verb. Do like fmt.Printf and tell the user we're
 		// confused.
@@ -156,24 +174,46 @@ func (s *state) formatEntries(err error) {
 	//   <complete error message>
 	//   (1) <details>
 	s.formatSingleLineOutput()
+	if s.opts.sortEntriesByStackDepth {
+		// Entries are printed from last to first, so that the entry
+		// with the deepest stack ends up on top.
+		sort.SliceStable(s.entries, func(i, j int) bool {
+			return s.entries[i].stackDepth < s.entries[j].stackDepth
+		})
+	}
 	s.finalBuf.WriteString("\n(1)")
 
 	s.printEntry(s.entries[len(s.entries)-1])
 
+	// If there is a limit to the number of entries (see
+	// SetMaxFormattedLayers()), the entries past it are not printed:
+	// entries with an index lower than stop are elided.
+	stop := 0
+	if limit := s.opts.maxFormattedLayers; limit > 0 && limit < len(s.entries) {
+		stop = len(s.entries) - limit
+	}
+
 	// All the entries that follow are printed as follows:
 	//
 	// Wraps: (N) <details>
 	//
-	for i, j := len(s.entries)-2, 2; i >= 0; i, j = i-1, j+1 {
-		fmt.Fprintf(&s.finalBuf, "\nWraps: (%d)", j)
+	// where the "Wraps:" header can be customized with SetWrapsHeader().
+	for i, j := len(s.entries)-2, 2; i >= stop; i, j = i-1, j+1 {
+		fmt.Fprintf(&s.finalBuf, "\n%s (%d)", s.opts.wrapsHeader, j)
 		entry := s.entries[i]
 		s.printEntry(entry)
 	}
+	if stop > 0 {
+		fmt.Fprintf(&s.finalBuf, "\n... (%d more layers elided)", stop)
+	}
 
 	// At the end, we link all the (N) references to the Go type of the
-	// error.
+	// error, unless disabled with SetShowErrorTypesTrailer().
+	if s.opts.hideErrorTypesTrailer {
+		return
+	}
 	s.finalBuf.WriteString("\nError types:")
-	for i, j := len(s.entries)-1, 1; i >= 0; i, j = i-1, j+1 {
+	for i, j := len(s.entries)-1, 1; i >= stop; i, j = i-1, j+1 {
 		fmt.Fprintf(&s.finalBuf, " (%d) %T", j, s.entries[i].err)
 	}
 }
@@ -206,10 +246,10 @@ func (s *state) printEntry(entry formatEntry) {
 		}
 		s.finalBuf.Write(entry.details)
 	}
-	if entry.stackTrace != nil {
+	if len(entry.stackTrace) > 0 {
 		s.finalBuf.WriteString("\n  -- stack trace:")
 		s.finalBuf.WriteString(strings.ReplaceAll(
-			fmt.Sprintf("%+v", entry.stackTrace),
+			formatStackTrace(entry.stackTrace, s.opts.stackPathMode),
 			"\n", string(detailSep)))
 		if entry.elidedStackTrace {
 			fmt.Fprintf(&s.finalBuf, "%s[...repeated from below...]", detailSep)
@@ -282,7 +322,7 @@ func (s *state) formatRecursive(err error, isOutermost, withDetail bool) {
 	seenTrace := false
 
 	printDone := false
-	for _, fn := range specialCases {
+	for _, fn := range getSpecialCases() {
 		if handled, desiredShortening := fn(err, (*printer)(s), cause == nil /* leaf */); handled {
 			printDone = true
 			if desiredShortening == nil {
@@ -298,6 +338,16 @@ func (s *state) formatRecursive(err error, isOutermost, withDetail bool) {
 	}
 	if !printDone {
 		switch v := err.(type) {
+		case SafeFormatter:
+			desiredShortening := v.SafeFormatError((*printer)(s))
+			if desiredShortening == nil {
+				// The error wants to elide the short messages from inner
+				// causes. Do it.
+				for i := range s.entries {
+					s.entries[i].elideShort = true
+				}
+			}
+
 		case Formatter:
 			desiredShortening := v.FormatError((*printer)(s))
 			if desiredShortening == nil {
@@ -342,12 +392,21 @@ func (s *state) formatRecursive(err error, isOutermost, withDetail bool) {
 
 	// If there's an embedded stack trace, also collect it.
 	// This will get either a stack from pkg/errors, or ours.
+	if st, ok := err.(StackTraceProvider); ok {
+		entry.stackDepth = len(st.StackTrace())
+	}
 	if !seenTrace {
 		if st, ok := err.(StackTraceProvider); ok {
-			entry.stackTrace, entry.elidedStackTrace = ElideSharedStackTraceSuffix(
-				s.lastStack,
-				st.StackTrace(),
-			)
+			if s.opts.sortEntriesByStackDepth {
+				// The entries will be reordered, so there is no
+				// meaningful "below" to elide the shared frames against.
+				entry.stackTrace = st.StackTrace()
+			} else {
+				entry.stackTrace, entry.elidedStackTrace = ElideSharedStackTraceSuffix(
+					s.lastStack,
+					st.StackTrace(),
+				)
+			}
 			s.lastStack = entry.stackTrace
 		}
 	}
@@ -388,11 +447,45 @@ func (s *state) collectEntry(err error) formatEntry {
 type safeErrorPrinterFn = func(err error, p Printer, isLeaf bool) (handled bool, next error)
 
 // specialCases is a list of functions to apply for special cases.
-var specialCases []safeErrorPrinterFn
+// It is guarded by a mutex since errors can be formatted concurrently
+// with a registration.
+var specialCases struct {
+	Registry
+	fns []safeErrorPrinterFn
+	// names are the names of the handlers in fns, in the same order.
+	names []string
+}
+
+// RegisterSpecialCasePrinter registers a handler. The name identifies
+// the handler in the list returned by ListRegisteredFormatters(), e.g.
+// "grpc status errors".
+//
+// Registration should happen at init time: it is safe to call
+// concurrently with the formatting of errors, but the errors being
+// formatted at that time may or may not use the new handler.
+func RegisterSpecialCasePrinter(name string, fn safeErrorPrinterFn) {
+	specialCases.Update(func() {
+		specialCases.fns = append(specialCases.fns, fn)
+		specialCases.names = append(specialCases.names, name)
+	})
+}
+
+// ListRegisteredFormatters returns the names of the handlers
+// registered with RegisterSpecialCasePrinter(), in registration order,
+// which is the order in which they are tried. This helps diagnose why
+// an error formats unexpectedly.
+func ListRegisteredFormatters() []string {
+	var names []string
+	specialCases.Read(func() { names = append(names, specialCases.names...) })
+
+	return names
+}
+
+// getSpecialCases returns a snapshot of the registered handlers.
+func getSpecialCases() (fns []safeErrorPrinterFn) {
+	specialCases.Read(func() { fns = specialCases.fns })
 
-// RegisterSpecialCasePrinter registers a handler.
-func RegisterSpecialCasePrinter(fn safeErrorPrinterFn) {
-	specialCases = append(specialCases, fn)
+	return fns
 }
 
 // formatSimple performs a best effort at extracting the details at a
@@ -401,7 +494,7 @@ func RegisterSpecialCasePrinter(fn safeErrorPrinterFn) {
 func (s *state) formatSimple(err, cause error) {
 	var pref string
 	if cause != nil {
-		pref = extractPrefix(err, cause)
+		pref = ExtractPrefix(err, cause)
 	} else {
 		pref = err.Error()
 	}
@@ -410,13 +503,13 @@ func (s *state) formatSimple(err, cause error) {
 	}
 }
 
-// extractPrefix extracts the prefix from a wrapper's error message.
+// ExtractPrefix extracts the prefix from a wrapper's error message.
 // For example,
 //    err := errors.New("bar")
 //    err = errors.Wrap(err, "foo")
-//    extractPrefix(err)
+//    ExtractPrefix(err, cause)
 // returns "foo".
-func extractPrefix(err, cause error) string {
+func ExtractPrefix(err, cause error) string {
 	causeSuffix := cause.Error()
 	errMsg := err.Error()
 
@@ -459,6 +552,7 @@ var detailSep = []byte("\n  | ")
 // state tracks error printing state. It implements fmt.State.
 type state struct {
 	fmt.State
+	opts                       formatOptions
 	entries                    []formatEntry
 	headBuf                    []byte
 	lastStack                  StackTrace
@@ -480,6 +574,7 @@ type formatEntry struct {
 	head             []byte
 	details          []byte
 	stackTrace       StackTrace
+	stackDepth       int
 	elideShort       bool
 	elidedStackTrace bool
 }
@@ -0,0 +1,66 @@
+package errbase
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type specialLeaf struct{ n int }
+
+func (e *specialLeaf) Error() string { return fmt.Sprintf("leaf %d", e.n) }
+
+// This test is meant to be run with -race.
+func TestSpecialCasePrinterConcurrentWithFormatting(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			RegisterSpecialCasePrinter(fmt.Sprintf("special leaf %d", i), func(err error, p Printer, isLeaf bool) (bool, error) {
+				if e, ok := err.(*specialLeaf); ok && e.n == i {
+					p.Printf("special leaf printer %d", i)
+
+					return true, nil
+				}
+
+				return false, nil
+			})
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			_ = fmt.Sprintf("%+v", Formattable(&specialLeaf{n: i}))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 8; i++ {
+		want := fmt.Sprintf("special leaf printer %d", i)
+		if got := fmt.Sprintf("%+v", Formattable(&specialLeaf{n: i})); !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestListRegisteredFormatters(t *testing.T) {
+	var savedFns []safeErrorPrinterFn
+	var savedNames []string
+	specialCases.Read(func() { savedFns, savedNames = specialCases.fns, specialCases.names })
+	defer specialCases.Update(func() { specialCases.fns, specialCases.names = savedFns, savedNames })
+
+	noop := func(error, Printer, bool) (bool, error) { return false, nil }
+	RegisterSpecialCasePrinter("test printer one", noop)
+	RegisterSpecialCasePrinter("test printer two", noop)
+
+	names := ListRegisteredFormatters()
+	if len(names) < 2 || names[len(names)-2] != "test printer one" || names[len(names)-1] != "test printer two" {
+		t.Errorf("expected the two printers last, in registration order, got %q", names)
+	}
+
+	// The result is a copy.
+	names[len(names)-1] = "changed"
+	if got := ListRegisteredFormatters(); got[len(got)-1] != "test printer two" {
+		t.Errorf("expected the registry unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,94 @@
+package errbase
+
+// This file holds the settings that customize the output of
+// FormatError(). They are process-wide, and meant to be set once at
+// initialization time. Like the other registries in this module, they
+// are guarded by a Registry so that setting them is safe even while
+// errors are being formatted concurrently. Each call to FormatError()
+// takes a snapshot of the settings, so that it renders consistently.
+
+// formatOptions is the set of settings that customize the output of
+// FormatError().
+type formatOptions struct {
+	// sortEntriesByStackDepth, when set, orders the entries printed by
+	// %+v by the depth of their stack trace instead of their position
+	// in the chain of causes.
+	sortEntriesByStackDepth bool
+	// wrapsHeader is the label printed in front of the number of every
+	// entry but the first one printed by %+v.
+	wrapsHeader string
+	// maxFormattedLayers, when positive, is the maximum number of
+	// entries printed by %+v.
+	maxFormattedLayers int
+	// stackPathMode is how the file paths of stack frames are printed
+	// by %+v.
+	stackPathMode StackPathMode
+	// hideErrorTypesTrailer, when set, omits the "Error types:" line at
+	// the end of the output of %+v.
+	hideErrorTypesTrailer bool
+}
+
+// defaultWrapsHeader is the default value of wrapsHeader.
+const defaultWrapsHeader = "Wraps:"
+
+var currentFormatOptions = struct {
+	Registry
+	formatOptions
+}{formatOptions: formatOptions{wrapsHeader: defaultWrapsHeader}}
+
+// getFormatOptions returns a snapshot of the current settings.
+func getFormatOptions() (res formatOptions) {
+	currentFormatOptions.Read(func() { res = currentFormatOptions.formatOptions })
+
+	return res
+}
+
+// SetSortEntriesByStackDepth controls the order of the "Wraps: (N)"
+// entries printed with %+v. By default, they follow the chain of
+// causes from the outermost error to the innermost. When enabled, the
+// entries are ordered by the depth of their stack trace instead, the
+// deepest first, so that the layer originating innermost in the code
+// prints first. Entries without a stack trace print last. Since the
+// order no longer follows the chain, shared stack frames are not
+// elided in this mode.
+func SetSortEntriesByStackDepth(enabled bool) {
+	currentFormatOptions.Update(func() { currentFormatOptions.sortEntriesByStackDepth = enabled })
+}
+
+// SetWrapsHeader changes the label printed with %+v in front of the
+// number of each cause of an error, "Wraps:" by default. For example,
+// with SetWrapsHeader("Caused by:") the causes are printed as
+// "Caused by: (N)". An empty header restores the default.
+func SetWrapsHeader(header string) {
+	if header == "" {
+		header = defaultWrapsHeader
+	}
+	currentFormatOptions.Update(func() { currentFormatOptions.wrapsHeader = header })
+}
+
+// SetMaxFormattedLayers limits the number of layers of an error that
+// are detailed when formatting it with %+v. Past n layers, the
+// formatter prints "... (M more layers elided)" and stops. This keeps
+// the output of pathologically deep chains readable. The error
+// message on the first line is always complete, and the chain itself
+// is unaffected, so Is() and As() still see all of it. A value of 0
+// or less, the default, means no limit.
+func SetMaxFormattedLayers(n int) {
+	currentFormatOptions.Update(func() { currentFormatOptions.maxFormattedLayers = n })
+}
+
+// SetStackPathMode changes how the file paths of the stack frames are
+// printed with %+v: absolute by default, as recorded by the compiler.
+// See StackPathMode for the alternatives. This only affects
+// formatting: the stack traces reported to Sentry are unchanged.
+func SetStackPathMode(mode StackPathMode) {
+	currentFormatOptions.Update(func() { currentFormatOptions.stackPathMode = mode })
+}
+
+// SetShowErrorTypesTrailer controls whether %+v ends with the
+// "Error types: (1) ... (2) ..." line, which links the numbered
+// entries to the Go types of the layers. Shown by default. When
+// hidden, the entries are still numbered.
+func SetShowErrorTypesTrailer(show bool) {
+	currentFormatOptions.Update(func() { currentFormatOptions.hideErrorTypesTrailer = !show })
+}
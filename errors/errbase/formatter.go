@@ -21,6 +21,17 @@
 
 package errbase
 
+// SafeFormatter is implemented by error leaf or wrapper types that want
+// to control how they are printed out, separating the short message
+// from the details shown with %+v.
+type SafeFormatter interface {
+	// SafeFormatError prints an error to the Printer.
+	// The return value decides what happens in the case
+	// SafeFormatError() is used to produce a "short" message,
+	// with the same semantics as Formatter.FormatError().
+	SafeFormatError(p Printer) (next error)
+}
+
 // A Formatter formats error messages.
 //
 // NB: Consider implementing SafeFormatter instead. This will ensure
@@ -66,3 +77,14 @@ type Printer interface {
 	// all.
 	Detail() bool
 }
+
+// WantsInlineDetail returns true if the error is being printed on a
+// single line with the space flag, e.g. with `% v`. In that case, the
+// wrappers that carry details not otherwise part of the message, e.g.
+// fields, can print a compact rendering of them, which is then
+// included in the single-line output. Plain `%v` is unaffected.
+func WantsInlineDetail(p Printer) bool {
+	s, ok := p.(*printer)
+
+	return ok && !s.wantDetail && s.Flag(' ')
+}
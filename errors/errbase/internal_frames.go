@@ -0,0 +1,69 @@
+package errbase
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// ourPkgPrefix is the import path of the root package of this module.
+// The functions of the packages under it are plumbing, not the code
+// that failed.
+var ourPkgPrefix = strings.TrimSuffix(reflect.TypeOf(state{}).PkgPath(), "/errbase")
+
+// keepInternalFrames is set when SetElideInternalFrames(false) was
+// called. It is read on every stack capture, hence the atomic.
+var keepInternalFrames int32
+
+// SetElideInternalFrames controls whether the stack traces captured
+// by the error constructors drop their leading frames that belong to
+// this module or to the Go runtime. This is enabled by default, so
+// that the top frame of a stack trace is always the code that created
+// the error, even when a constructor is called through a helper of
+// this module, e.g. RecoverHTTP(). Frames in test files are never
+// dropped, and a stack trace made only of such frames is kept whole.
+func SetElideInternalFrames(enabled bool) {
+	var v int32
+	if !enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&keepInternalFrames, v)
+}
+
+// TrimInternalFrames returns pcs, the program counters of a stack
+// trace being captured, without its leading internal frames, as
+// configured with SetElideInternalFrames(). Only the frames that are
+// dropped, and the first one kept, are resolved to functions.
+func TrimInternalFrames(pcs []uintptr) []uintptr {
+	if atomic.LoadInt32(&keepInternalFrames) != 0 {
+		return pcs
+	}
+	for i, pc := range pcs {
+		if !isInternalFrame(pc) {
+			return pcs[i:]
+		}
+	}
+
+	return pcs
+}
+
+// isInternalFrame returns true if pc is in a function of this module,
+// outside of a test file, or of the Go runtime, e.g. runtime.gopanic
+// when the error is created while recovering from a panic.
+func isInternalFrame(pc uintptr) bool {
+	fn := runtime.FuncForPC(pc - 1)
+	if fn == nil {
+		return false
+	}
+	pkg := funcPackagePath(fn.Name())
+	if pkg == "runtime" {
+		return true
+	}
+	if pkg != ourPkgPrefix && !strings.HasPrefix(pkg, ourPkgPrefix+"/") {
+		return false
+	}
+	file, _ := fn.FileLine(pc - 1)
+
+	return !strings.HasSuffix(file, "_test.go")
+}
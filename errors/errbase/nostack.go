@@ -0,0 +1,82 @@
+package errbase
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Go has no goroutine-local storage, so WithoutStacks() marks the
+// region by running fn from a dedicated function, runNoStackRegion(),
+// whose frame the error constructors look for in the stack of their
+// own goroutine. Walking the stack is only done while at least one
+// such region is active anywhere in the process.
+
+// noStackRegions is the number of regions currently active.
+var noStackRegions int32
+
+// noStackRegionPC is the return address of the call to fn in
+// runNoStackRegion(), which identifies its frame in a stack trace.
+var noStackRegionPC uintptr
+
+func init() {
+	runNoStackRegion(func() {
+		var pcs [1]uintptr
+		runtime.Callers(2, pcs[:])
+		noStackRegionPC = pcs[0]
+	})
+}
+
+// WithoutStacks runs fn, and makes the error constructors called by
+// fn skip capturing a stack trace. This is meant for bulk operations
+// that create many errors, where the cost of the stack traces would
+// dominate. The errors are otherwise unchanged.
+//
+// Caveats:
+//   - only the goroutine calling WithoutStacks is affected: the
+//     goroutines started by fn capture stacks as usual.
+//   - the constructors find the region by walking up the stack, so
+//     an error created more than 256 frames below WithoutStacks
+//     captures a stack as usual.
+//   - while any goroutine is running WithoutStacks, the error
+//     constructors called outside of a region walk the stack once
+//     more before capturing it.
+func WithoutStacks(fn func()) {
+	atomic.AddInt32(&noStackRegions, 1)
+	defer atomic.AddInt32(&noStackRegions, -1)
+	runNoStackRegion(fn)
+}
+
+// runNoStackRegion marks the frames of fn as being in a region
+// without stacks. It must not be inlined, so that its frame appears in
+// the stack.
+//
+//go:noinline
+func runNoStackRegion(fn func()) {
+	fn()
+}
+
+// StacksSuppressed returns true if the current goroutine is running
+// inside WithoutStacks(), in which case the error constructors should
+// not capture a stack trace.
+func StacksSuppressed() bool {
+	if atomic.LoadInt32(&noStackRegions) == 0 {
+		return false
+	}
+	// Regions usually enclose the errors they create closely, so look
+	// at the nearest frames first, and only walk further up if needed.
+	var pcs [256]uintptr
+	for n := 8; ; n *= 4 {
+		if n > len(pcs) {
+			n = len(pcs)
+		}
+		got := runtime.Callers(2, pcs[:n])
+		for _, pc := range pcs[:got] {
+			if pc == noStackRegionPC {
+				return true
+			}
+		}
+		if got < n || n == len(pcs) {
+			return false
+		}
+	}
+}
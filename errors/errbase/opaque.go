@@ -0,0 +1,150 @@
+package errbase
+
+import (
+	"fmt"
+	"strings"
+)
+
+// opaqueLeaf stands in for a leaf error whose original type is not
+// available, e.g. after the error was decoded from its serialized form.
+type opaqueLeaf struct {
+	msg      string
+	typeName string
+}
+
+// NewOpaqueLeaf creates a leaf error with the given message, standing
+// in for an error of the given original type.
+func NewOpaqueLeaf(msg, typeName string) error {
+	return &opaqueLeaf{msg: msg, typeName: typeName}
+}
+
+var (
+	_ error         = (*opaqueLeaf)(nil)
+	_ fmt.Formatter = (*opaqueLeaf)(nil)
+)
+
+func (l *opaqueLeaf) Error() string { return l.msg }
+
+// Format implements the fmt.Formatter interface.
+func (l *opaqueLeaf) Format(s fmt.State, verb rune) { FormatError(l, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (l *opaqueLeaf) SafeFormatError(p Printer) (next error) {
+	p.Print(l.msg)
+	if p.Detail() {
+		p.Printf("original type: %s", l.typeName)
+	}
+
+	return nil
+}
+
+// opaqueWrapper stands in for a wrapper error whose original type is
+// not available, or that could not be copied faithfully.
+type opaqueWrapper struct {
+	cause      error
+	prefix     string
+	typeName   string
+	stackTrace StackTrace
+}
+
+// NewOpaqueWrapper creates a wrapper error around cause with the
+// given message prefix, standing in for an error of the given
+// original type.
+func NewOpaqueWrapper(cause error, prefix, typeName string) error {
+	return &opaqueWrapper{cause: cause, prefix: prefix, typeName: typeName}
+}
+
+var (
+	_ error         = (*opaqueWrapper)(nil)
+	_ fmt.Formatter = (*opaqueWrapper)(nil)
+)
+
+func (w *opaqueWrapper) Error() string {
+	if w.prefix == "" {
+		return w.cause.Error()
+	}
+
+	return w.prefix + ": " + w.cause.Error()
+}
+
+func (w *opaqueWrapper) Cause() error  { return w.cause }
+func (w *opaqueWrapper) Unwrap() error { return w.cause }
+
+// StackTrace implements the StackTraceProvider interface.
+func (w *opaqueWrapper) StackTrace() StackTrace { return w.stackTrace }
+
+// Format implements the fmt.Formatter interface.
+func (w *opaqueWrapper) Format(s fmt.State, verb rune) { FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *opaqueWrapper) SafeFormatError(p Printer) (next error) {
+	if len(w.prefix) > 0 {
+		p.Print(w.prefix)
+	}
+	if p.Detail() {
+		p.Printf("original type: %s", w.typeName)
+	}
+
+	return w.cause
+}
+
+// opaqueMulti stands in for a multi-error, i.e. an error with a method
+// Unwrap() []error such as those built by the errors.Join() of the
+// standard library, whose branches had to be replaced.
+type opaqueMulti struct {
+	causes   []error
+	typeName string
+}
+
+// NewOpaqueMulti creates a multi-error with the given branches,
+// standing in for an error of the given original type. Like the
+// errors.Join() of the standard library, its message is the messages
+// of the branches, one per line.
+func NewOpaqueMulti(causes []error, typeName string) error {
+	return &opaqueMulti{causes: causes, typeName: typeName}
+}
+
+var (
+	_ error         = (*opaqueMulti)(nil)
+	_ fmt.Formatter = (*opaqueMulti)(nil)
+)
+
+func (m *opaqueMulti) Error() string {
+	msgs := make([]string, len(m.causes))
+	for i, c := range m.causes {
+		msgs[i] = c.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+func (m *opaqueMulti) Unwrap() []error { return m.causes }
+
+// Format implements the fmt.Formatter interface.
+func (m *opaqueMulti) Format(s fmt.State, verb rune) { FormatError(m, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (m *opaqueMulti) SafeFormatError(p Printer) (next error) {
+	p.Print(m.Error())
+	if p.Detail() {
+		p.Printf("original type: %s", m.typeName)
+	}
+
+	return nil
+}
+
+// TypeName returns the name of the Go type of err, as printed by %T.
+// For errors standing in for another type, e.g. after decoding, the
+// name of the original type is returned instead.
+func TypeName(err error) string {
+	switch e := err.(type) {
+	case *opaqueLeaf:
+		return e.typeName
+	case *opaqueWrapper:
+		return e.typeName
+	case *opaqueMulti:
+		return e.typeName
+	}
+
+	return fmt.Sprintf("%T", err)
+}
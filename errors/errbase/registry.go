@@ -0,0 +1,55 @@
+package errbase
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Registry guards a process-wide registry or setting of this module,
+// e.g. the rewrappers or the format options. It is meant to be
+// embedded in an anonymous struct holding the data it guards:
+//
+//	var rewrappers = struct {
+//		Registry
+//		fns map[reflect.Type]RewrapFn
+//	}{fns: map[reflect.Type]RewrapFn{}}
+//
+//	func RegisterRewrapper(theType error, fn RewrapFn) {
+//		rewrappers.Update(func() { rewrappers.fns[reflect.TypeOf(theType)] = fn })
+//	}
+//
+//	func getRewrapper(err error) (fn RewrapFn, ok bool) {
+//		rewrappers.Read(func() { fn, ok = rewrappers.fns[reflect.TypeOf(err)] })
+//
+//		return fn, ok
+//	}
+//
+// The Register*() and Set*() functions built this way should be
+// called at init time, but are safe to call concurrently with the
+// handling of errors, which only reads the registries. The zero value
+// is ready to use.
+type Registry struct {
+	mu sync.RWMutex
+}
+
+// Read calls fn with the registry locked for reading. fn must not
+// call Update() on the same registry.
+func (r *Registry) Read(fn func()) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fn()
+}
+
+// Update calls fn with the registry locked for writing. fn must not
+// call Read() or Update() on the same registry.
+func (r *Registry) Update(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn()
+	atomic.AddUint64(&registryGeneration, 1)
+}
+
+// registryGeneration is incremented by every Registry.Update(), so
+// that the format cache can tell when a setting that may affect the
+// output of FormatError() changed.
+var registryGeneration uint64
@@ -0,0 +1,56 @@
+package errbase
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// This test is meant to be run with -race: it registers and reads
+// concurrently, as happens when a package registers something at init
+// time while errors are already being handled.
+
+type raceWrapper struct{ cause error }
+
+func (w *raceWrapper) Error() string { return fmt.Sprintf("race: %v", w.cause) }
+func (w *raceWrapper) Unwrap() error { return w.cause }
+
+func TestRegistryConcurrentAccess(t *testing.T) {
+	var reg = struct {
+		Registry
+		names []string
+	}{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			reg.Update(func() { reg.names = append(reg.names, fmt.Sprint(i)) })
+			RegisterRewrapper((*raceWrapper)(nil), func(err, newCause error) error {
+				return &raceWrapper{cause: newCause}
+			})
+			RegisterSpecialCasePrinter(fmt.Sprintf("race %d", i), func(error, Printer, bool) (bool, error) {
+				return false, nil
+			})
+		}(i)
+		go func() {
+			defer wg.Done()
+			var n int
+			reg.Read(func() { n = len(reg.names) })
+			_ = n
+			_ = Rewrap(&raceWrapper{cause: errors.New("a")}, errors.New("b"))
+			_ = ListRegisteredFormatters()
+			_ = fmt.Sprintf("%+v", &raceWrapper{cause: errors.New("a")})
+		}()
+	}
+	wg.Wait()
+
+	if len(reg.names) != 8 {
+		t.Errorf("expected 8 registrations, got %d", len(reg.names))
+	}
+	if _, ok := getRewrapper(&raceWrapper{}); !ok {
+		t.Errorf("expected the rewrapper to be registered")
+	}
+}
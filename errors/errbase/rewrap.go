@@ -0,0 +1,70 @@
+package errbase
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// This file provides the machinery to rebuild a chain of errors with
+// one of its causes replaced. Each wrapper type knows best how to
+// copy itself, so the packages defining wrapper types register a
+// rewrapper for them at init time. Wrappers that we know nothing
+// about (e.g. from other libraries) are replaced by an opaqueWrapper
+// that retains their message prefix and stack trace, if any.
+
+// RewrapFn is the type of a function that returns a copy of the
+// wrapper err, around newCause instead of its original cause.
+type RewrapFn = func(err, newCause error) error
+
+// rewrappers is the set of registered rewrappers, keyed by the type of
+// wrapper they know how to copy.
+var rewrappers = struct {
+	Registry
+	fns map[reflect.Type]RewrapFn
+}{fns: map[reflect.Type]RewrapFn{}}
+
+// RegisterRewrapper registers a function able to copy wrappers of the
+// same type as theType. This should be called at init time, but is
+// safe to call concurrently with Rewrap().
+func RegisterRewrapper(theType error, fn RewrapFn) {
+	rewrappers.Update(func() { rewrappers.fns[reflect.TypeOf(theType)] = fn })
+}
+
+// getRewrapper returns the rewrapper registered for the type of err.
+func getRewrapper(err error) (fn RewrapFn, ok bool) {
+	rewrappers.Read(func() { fn, ok = rewrappers.fns[reflect.TypeOf(err)] })
+
+	return fn, ok
+}
+
+// Rewrap returns a copy of the wrapper err with newCause as its
+// cause. If no rewrapper was registered for the type of err, the
+// result is an opaque wrapper that retains the message prefix and
+// stack trace of err.
+func Rewrap(err, newCause error) error {
+	if fn, ok := getRewrapper(err); ok {
+		return fn(err, newCause)
+	}
+
+	w := &opaqueWrapper{
+		cause:    newCause,
+		typeName: fmt.Sprintf("%T", err),
+	}
+	if cause := UnwrapOnce(err); cause != nil {
+		w.prefix = ExtractPrefix(err, cause)
+	}
+	if st, ok := err.(StackTraceProvider); ok {
+		w.stackTrace = st.StackTrace()
+	}
+
+	return w
+}
+
+func init() {
+	RegisterRewrapper((*opaqueWrapper)(nil), func(err, newCause error) error {
+		w := *(err.(*opaqueWrapper))
+		w.cause = newCause
+
+		return &w
+	})
+}
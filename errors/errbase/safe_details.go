@@ -0,0 +1,25 @@
+package errbase
+
+// SafeDetailer is implemented by error leaf or wrapper types that can
+// provide details that are safe to report, i.e. free of PII, e.g. to
+// Sentry.
+type SafeDetailer interface {
+	SafeDetails() []string
+}
+
+// GetSafeDetails collects the safe details of err and all its causes,
+// from the outermost to the innermost. The layers that do not
+// implement SafeDetailer contribute nothing: their contents are
+// presumed unsafe.
+func GetSafeDetails(err error) []string {
+	var details []string
+	Walk(err, func(c error) bool {
+		if sd, ok := c.(SafeDetailer); ok {
+			details = append(details, sd.SafeDetails()...)
+		}
+
+		return true
+	})
+
+	return details
+}
@@ -0,0 +1,131 @@
+package errbase
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// StackPathMode is how the file paths of stack frames are printed. See
+// SetStackPathMode().
+type StackPathMode int
+
+const (
+	// StackPathAbsolute prints the absolute file paths, e.g.
+	// "/home/me/src/app/server/handler.go". This is the default.
+	StackPathAbsolute StackPathMode = iota
+	// StackPathRelative prints the file paths relative to the root of
+	// their module, e.g. "server/handler.go". The files of the
+	// standard library are printed relative to GOROOT/src, e.g.
+	// "net/http/server.go".
+	StackPathRelative
+	// StackPathBaseOnly only prints the file names, e.g. "handler.go".
+	StackPathBaseOnly
+)
+
+// formatStackTrace renders st like %+v does, with the file paths
+// printed according to mode.
+func formatStackTrace(st StackTrace, mode StackPathMode) string {
+	if mode == StackPathAbsolute {
+		return fmt.Sprintf("%+v", st)
+	}
+	var b strings.Builder
+	for _, f := range st {
+		// A frame prints as "function\n\tfile:line".
+		s := fmt.Sprintf("%+v", f)
+		if i := strings.Index(s, "\n\t"); i >= 0 {
+			fileLine := s[i+2:]
+			if j := strings.LastIndexByte(fileLine, ':'); j >= 0 {
+				s = s[:i+2] + shortenPath(fileLine[:j], f, mode) + fileLine[j:]
+			}
+		}
+		b.WriteByte('\n')
+		b.WriteString(s)
+	}
+
+	return b.String()
+}
+
+// shortenPath returns the path of file, the source file of frame f,
+// according to mode.
+func shortenPath(file string, f StackFrame, mode StackPathMode) string {
+	if mode == StackPathBaseOnly {
+		return path.Base(file)
+	}
+	fn := runtime.FuncForPC(uintptr(f) - 1)
+	if fn == nil {
+		return file
+	}
+	pkgPath := funcPackagePath(fn.Name())
+	if pkgPath == "main" {
+		pkgPath = mainPackagePath()
+	}
+	if modPath := modulePathOf(pkgPath); modPath != "" {
+		pkgPath = strings.TrimPrefix(strings.TrimPrefix(pkgPath, modPath), "/")
+	}
+
+	return path.Join(pkgPath, path.Base(file))
+}
+
+// funcPackagePath returns the import path of the package of the
+// function with the given fully qualified name, e.g.
+// "github.com/a/b/pkg" for "github.com/a/b/pkg.(*T).Method".
+func funcPackagePath(name string) string {
+	slash := strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+
+	return name
+}
+
+// buildInfo is the information on the modules of the binary, read
+// once.
+var buildInfo struct {
+	once     sync.Once
+	mainPath string
+	modules  []string
+}
+
+func loadBuildInfo() {
+	buildInfo.once.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		buildInfo.mainPath = info.Path
+		buildInfo.modules = append(buildInfo.modules, info.Main.Path)
+		for _, m := range info.Deps {
+			buildInfo.modules = append(buildInfo.modules, m.Path)
+		}
+	})
+}
+
+// mainPackagePath returns the import path of the main package of the
+// binary, or "main" if it is unknown.
+func mainPackagePath() string {
+	loadBuildInfo()
+	if buildInfo.mainPath == "" {
+		return "main"
+	}
+
+	return buildInfo.mainPath
+}
+
+// modulePathOf returns the path of the module of the binary that
+// contains the package pkgPath, or "" if there is none, e.g. for the
+// packages of the standard library.
+func modulePathOf(pkgPath string) string {
+	loadBuildInfo()
+	best := ""
+	for _, m := range buildInfo.modules {
+		if m != "" && len(m) > len(best) && (pkgPath == m || strings.HasPrefix(pkgPath, m+"/")) {
+			best = m
+		}
+	}
+
+	return best
+}
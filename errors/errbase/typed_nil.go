@@ -0,0 +1,26 @@
+package errbase
+
+import "reflect"
+
+// IsTypedNil returns true if err is a non-nil interface holding a nil
+// pointer (or a nil map, slice, func or channel), as in the classic Go
+// mistake:
+//
+//	var e *MyError // nil
+//	return e       // a non-nil error
+//
+// Such an error compares unequal to nil, yet usually panics when its
+// methods are called. The constructors of this module that wrap an
+// error treat it like nil.
+func IsTypedNil(err error) bool {
+	if err == nil {
+		return false
+	}
+	v := reflect.ValueOf(err)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+
+	return false
+}
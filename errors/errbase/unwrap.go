@@ -53,3 +53,97 @@ func UnwrapAll(err error) error {
 
 	return err
 }
+
+// Depth returns the number of layers in the chain of causes of err,
+// including err itself. The depth of a nil error is 0.
+func Depth(err error) int {
+	depth := 0
+	for c := err; c != nil; c = UnwrapOnce(c) {
+		depth++
+	}
+
+	return depth
+}
+
+// StackCount returns the number of distinct stack traces in the chain
+// of err: the layers that carry a stack trace, except those whose
+// stack trace is identical to the closest one below them. A
+// well-formed error usually has 1. More suggest redundant calls to
+// WithStack() or Wrap(), e.g. at every level of a call chain. The
+// layers are visited as with Walk(), so the stack traces in all the
+// branches of a multi-error are counted.
+func StackCount(err error) int {
+	var stacks []StackTrace
+	Walk(err, func(c error) bool {
+		if st, ok := c.(StackTraceProvider); ok {
+			if t := st.StackTrace(); len(t) != 0 {
+				stacks = append(stacks, t)
+			}
+		}
+
+		return true
+	})
+	count := 0
+	var prev StackTrace
+	for i := len(stacks) - 1; i >= 0; i-- {
+		if !sameStackTrace(stacks[i], prev) {
+			count++
+		}
+		prev = stacks[i]
+	}
+
+	return count
+}
+
+func sameStackTrace(a, b StackTrace) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UnwrapN applies UnwrapOnce() n times to err, and returns the error
+// found n levels down the chain of causes, or nil if the chain is
+// shorter than that. UnwrapN(err, 0) returns err itself.
+func UnwrapN(err error, n int) error {
+	for ; n > 0 && err != nil; n-- {
+		err = UnwrapOnce(err)
+	}
+
+	return err
+}
+
+// Walk calls fn on err and on each of its causes, from the outermost
+// to the innermost, until fn returns false. When an error in the chain
+// has a method Unwrap() []error, as with the errors.Join() of the
+// standard library and other multi-errors, Walk descends into each of
+// its branches in order, depth-first. Walk returns false if fn stopped
+// the walk, and true otherwise.
+//
+// This is the traversal used by the accessors of this library that
+// look for information anywhere in the chain of an error, e.g. its
+// kind or its fields, so that they also see through multi-errors.
+func Walk(err error, fn func(error) bool) bool {
+	for c := err; c != nil; c = UnwrapOnce(c) {
+		if !fn(c) {
+			return false
+		}
+		if m, ok := c.(interface{ Unwrap() []error }); ok {
+			for _, branch := range m.Unwrap() {
+				if !Walk(branch, fn) {
+					return false
+				}
+			}
+
+			return true
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,111 @@
+// Package errtest provides helpers to inspect errors in tests.
+package errtest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/anotherr/errors"
+)
+
+// LogError logs err to t in detail: the full %+v rendering, followed
+// by a breakdown of its kind, fields, depth and source. This gives
+// test failures a consistent and readable dump of the errors involved.
+func LogError(t testing.TB, err error) {
+	t.Helper()
+	if err == nil {
+		t.Logf("error: <nil>")
+
+		return
+	}
+	t.Logf("error: %+v", err)
+	t.Logf("kind: %s", errors.GetKind(err))
+	for i, fields := range errors.GetAllFields(err) {
+		t.Logf("fields[%d]: %v", i, fields)
+	}
+	t.Logf("depth: %d", errors.Depth(err))
+	if file, line, fn, ok := errors.GetOneLineSource(err); ok {
+		t.Logf("source: %s:%d %s", file, line, fn)
+	} else {
+		t.Logf("source: unknown")
+	}
+}
+
+// AssertKind checks that the kind of err, as reported by
+// errors.EffectiveKind(), is kind, e.g. errors.NotFoundKind. This is
+// the kind that the HTTP helpers and reporting of the errors package
+// act on, so it includes the kinds inferred from the registered
+// mappings and the errors of the os package. Otherwise it marks the
+// test as failed, and logs err. Returns whether the check passed.
+func AssertKind(t testing.TB, err error, kind errors.ErrorKind) bool {
+	t.Helper()
+	if got := errors.EffectiveKind(err); got != kind {
+		t.Errorf("expected error of kind %q, got kind %q for error: %+v", kind, got, err)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertKindIn checks that the kind of err, as reported by
+// errors.EffectiveKind() like for AssertKind, is one of kinds, e.g.
+// errors.NotFoundKind or errors.InvalidInputKind for a client error of
+// some kind. Otherwise it marks the test as failed, and logs err.
+// Returns whether the check passed.
+func AssertKindIn(t testing.TB, err error, kinds ...errors.ErrorKind) bool {
+	t.Helper()
+	got := errors.EffectiveKind(err)
+	for _, kind := range kinds {
+		if got == kind {
+			return true
+		}
+	}
+	t.Errorf("expected error of kind in %q, got kind %q for error: %+v", kinds, got, err)
+
+	return false
+}
+
+// AssertHasField checks that err has a field key, at any layer, with
+// a value equal to value as per reflect.DeepEqual. If key is set at
+// several layers, the outermost value is checked. Otherwise it marks
+// the test as failed, and logs err. Returns whether the check passed.
+func AssertHasField(t testing.TB, err error, key string, value interface{}) bool {
+	t.Helper()
+	for _, fields := range errors.GetAllFields(err) {
+		got, ok := fields[key]
+		if !ok {
+			continue
+		}
+		if !reflect.DeepEqual(got, value) {
+			t.Errorf("expected field %q to be %#v, got %#v in error: %+v", key, value, got, err)
+
+			return false
+		}
+
+		return true
+	}
+	t.Errorf("expected field %q in error: %+v", key, err)
+
+	return false
+}
+
+// AssertContainsMessage checks that the message of err contains
+// substr. Otherwise it marks the test as failed, and logs err.
+// Returns whether the check passed.
+func AssertContainsMessage(t testing.TB, err error, substr string) bool {
+	t.Helper()
+	if err == nil {
+		t.Errorf("expected error containing %q, got nil", substr)
+
+		return false
+	}
+	if !strings.Contains(err.Error(), substr) {
+		t.Errorf("expected error containing %q, got %q", substr, err.Error())
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,125 @@
+package errtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/anotherr/errors"
+)
+
+// fakeT records what the helpers log and report, instead of failing
+// the test that calls them.
+type fakeT struct {
+	testing.TB
+	logs   []string
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestLogError(t *testing.T) {
+	ft := &fakeT{}
+	LogError(ft, errors.Wrap(errors.NotFound("no user", errors.Fields{"user": 42}), "loading"))
+
+	out := strings.Join(ft.logs, "\n")
+	for _, section := range []string{
+		"error: loading: not found\n(1)",
+		"\nkind: not found\n",
+		"\nfields[0]: map[message:no user user:42]\n",
+		"\ndepth: ",
+		"\nsource: ",
+		"errtest_test.go:",
+	} {
+		if !strings.Contains(out, section) {
+			t.Errorf("expected %q in:\n%s", section, out)
+		}
+	}
+
+	ft = &fakeT{}
+	LogError(ft, nil)
+	if len(ft.logs) != 1 || ft.logs[0] != "error: <nil>" {
+		t.Errorf("expected a single line for nil, got %q", ft.logs)
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	err := errors.Wrap(errors.NotFound("no user", errors.Fields{"user": 42}), "loading")
+
+	for _, tc := range []struct {
+		name   string
+		check  func(t testing.TB) bool
+		passes bool
+	}{
+		{"AssertKind match", func(t testing.TB) bool { return AssertKind(t, err, errors.NotFoundKind) }, true},
+		{"AssertKind mismatch", func(t testing.TB) bool { return AssertKind(t, err, errors.InternalKind) }, false},
+		{"AssertHasField match", func(t testing.TB) bool { return AssertHasField(t, err, "user", 42) }, true},
+		{"AssertHasField wrong value", func(t testing.TB) bool { return AssertHasField(t, err, "user", 43) }, false},
+		{"AssertHasField missing", func(t testing.TB) bool { return AssertHasField(t, err, "email", "x") }, false},
+		{"AssertContainsMessage match", func(t testing.TB) bool { return AssertContainsMessage(t, err, "loading") }, true},
+		{"AssertContainsMessage mismatch", func(t testing.TB) bool { return AssertContainsMessage(t, err, "saving") }, false},
+		{"AssertContainsMessage nil", func(t testing.TB) bool { return AssertContainsMessage(t, nil, "loading") }, false},
+	} {
+		ft := &fakeT{}
+		if got := tc.check(ft); got != tc.passes {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.passes, got)
+		}
+		if failed := len(ft.errors) > 0; failed == tc.passes {
+			t.Errorf("%s: expected the test to fail=%v, got errors %q", tc.name, !tc.passes, ft.errors)
+		}
+	}
+}
+
+func TestAssertKindFailureMessage(t *testing.T) {
+	ft := &fakeT{}
+	AssertKind(ft, errors.NotFound("no user"), errors.InternalKind)
+
+	if len(ft.errors) != 1 {
+		t.Fatalf("expected one failure, got %q", ft.errors)
+	}
+	msg := ft.errors[0]
+	for _, s := range []string{`expected error of kind "internal error"`, `got kind "not found"`, "stack trace:"} {
+		if !strings.Contains(msg, s) {
+			t.Errorf("expected %q in the failure:\n%s", s, msg)
+		}
+	}
+}
+
+func TestAssertKindIn(t *testing.T) {
+	err := errors.Wrap(errors.NotFound("no user"), "loading")
+
+	ft := &fakeT{}
+	if !AssertKindIn(ft, err, errors.InvalidInputKind, errors.NotFoundKind) || len(ft.errors) != 0 {
+		t.Errorf("expected the check to pass, got errors %q", ft.errors)
+	}
+
+	ft = &fakeT{}
+	if AssertKindIn(ft, err, errors.InternalKind, errors.InvalidInputKind) || len(ft.errors) != 1 {
+		t.Fatalf("expected the check to fail once, got errors %q", ft.errors)
+	}
+	for _, s := range []string{`["internal error" "invalid input error"]`, `got kind "not found"`, "stack trace:"} {
+		if !strings.Contains(ft.errors[0], s) {
+			t.Errorf("expected %q in the failure:\n%s", s, ft.errors[0])
+		}
+	}
+}
+
+func TestAssertKindUsesEffectiveKind(t *testing.T) {
+	_, openErr := os.Open(filepath.Join(t.TempDir(), "missing.txt"))
+	err := errors.Wrap(openErr, "open")
+
+	ft := &fakeT{}
+	if !AssertKind(ft, err, errors.NotFoundKind) || !AssertKindIn(ft, err, errors.NotFoundKind) {
+		t.Errorf("expected both checks to pass, got errors %q", ft.errors)
+	}
+}
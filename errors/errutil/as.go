@@ -32,8 +32,10 @@ import (
 // matches the type to which target points.
 //
 // Note: this implementation differs from that of xerrors as follows:
-// - it also supports recursing through causes with Cause().
-// - if it detects an API use error, its panic object is a valid error.
+//   - it also supports recursing through causes with Cause().
+//   - it descends into the branches of multi-errors, i.e. the errors
+//     with a method Unwrap() []error, like the errors.As() of Go 1.20.
+//   - if it detects an API use error, its panic object is a valid error.
 func As(err error, target interface{}) bool {
 	if target == nil {
 		panic(New("errors.As: target cannot be nil"))
@@ -51,18 +53,65 @@ func As(err error, target interface{}) bool {
 	}
 
 	targetType := typ.Elem()
-	for c := err; c != nil; c = errbase.UnwrapOnce(c) {
+
+	return !errbase.Walk(err, func(c error) bool {
 		if reflect.TypeOf(c).AssignableTo(targetType) {
 			val.Elem().Set(reflect.ValueOf(c))
 
-			return true
-		}
-		if x, ok := c.(interface{ As(interface{}) bool }); ok && x.As(target) {
-			return true
+			return false
 		}
-	}
+		x, ok := c.(interface{ As(interface{}) bool })
 
-	return false
+		return !ok || !x.As(target)
+	})
 }
 
 var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// AsAll is like As, but finds all the errors in err's chain that match
+// the element type of the slice to which target points, instead of
+// only the first one. The matching errors are appended to the slice,
+// from the outermost to the innermost, and AsAll returns true if
+// there was at least one. Matching is by assignability only; the As()
+// methods of errors are not used, as they can only produce one value.
+//
+// When an error in the chain has a method Unwrap() []error, as used
+// by multi-errors, AsAll descends into each of its branches in order.
+//
+// AsAll panics if target is not a non-nil pointer to a slice of a type
+// which implements error or is of interface type.
+//
+// Usage:
+//
+//	var errs []*MyError
+//	if errors.AsAll(err, &errs) { ... }
+func AsAll(err error, target interface{}) bool {
+	if target == nil {
+		panic(New("errors.AsAll: target cannot be nil"))
+	}
+
+	// As with As(), we use introspection: go.mod targets Go 1.16,
+	// which has no type parameters.
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() || typ.Elem().Kind() != reflect.Slice {
+		panic(Newf("errors.AsAll: target must be a non-nil pointer to a slice, found %T", target))
+	}
+	targetType := typ.Elem().Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType) {
+		panic(Newf("errors.AsAll: *target must be a slice of interface or of a type implementing error, found %T", target))
+	}
+
+	found := false
+	slice := val.Elem()
+	errbase.Walk(err, func(c error) bool {
+		if reflect.TypeOf(c).AssignableTo(targetType) {
+			slice.Set(reflect.Append(slice, reflect.ValueOf(c)))
+			found = true
+		}
+
+		return true
+	})
+
+	return found
+}
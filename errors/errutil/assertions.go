@@ -0,0 +1,62 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package errutil
+
+import "github.com/StevenACoffman/anotherr/errors/assert"
+
+// AssertionFailedf creates an internal error.
+//
+// Detail is shown:
+// - stack trace via `errors.GetSafeDetails()`, without the message.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func AssertionFailedf(format string, args ...interface{}) error {
+	return AssertionFailedWithDepthf(1, format, args...)
+}
+
+// AssertionFailedWithDepthf creates an internal error
+// with a stack trace collected at the specified depth.
+// See the doc of `AssertionFailedf()` for more details.
+func AssertionFailedWithDepthf(depth int, format string, args ...interface{}) error {
+	err := newWithDepthf(1+depth, format, args...)
+	err = assert.WithAssertionFailure(err)
+
+	return NotifyCreated(err)
+}
+
+// NewAssertionErrorWithWrappedErrf wraps an error and turns it into
+// an assertion error. The original error remains visible to Is() and
+// As() through the chain.
+//
+// Detail is shown:
+// - stack trace and formatted message via `errors.GetSafeDetails()`,
+// followed by the safe details of the original error.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func NewAssertionErrorWithWrappedErrf(origErr error, format string, args ...interface{}) error {
+	return NewAssertionErrorWithWrappedErrDepthf(1, origErr, format, args...)
+}
+
+// NewAssertionErrorWithWrappedErrDepthf is like
+// NewAssertionErrorWithWrappedErrf but the depth at which the call
+// stack is captured can be specified.
+func NewAssertionErrorWithWrappedErrDepthf(
+	depth int, origErr error, format string, args ...interface{},
+) error {
+	err := wrapWithDepthf(1+depth, origErr, format, args...)
+	err = assert.WithAssertionFailure(err)
+
+	return NotifyCreated(err)
+}
@@ -0,0 +1,65 @@
+package errutil
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+	"github.com/StevenACoffman/anotherr/errors/withstack"
+)
+
+// NewLazyf is like Newf, except that the message is only formatted the
+// first time it is needed, e.g. by Error(), and then memoized. This
+// saves the cost of formatting on hot paths where the error is often
+// handled without ever being printed.
+//
+// Since the formatting is deferred, the args must not be modified
+// after the call. The %w verb is not supported: use Wrapf() to wrap
+// an error.
+func NewLazyf(format string, args ...interface{}) error {
+	return NewLazyWithDepthf(1, format, args...)
+}
+
+// NewLazyWithDepthf is like NewLazyf except the depth to capture the
+// stack trace is configurable.
+func NewLazyWithDepthf(depth int, format string, args ...interface{}) error {
+	err := error(&lazyLeafError{format: format, args: args})
+
+	return NotifyCreated(withstack.WithStackDepth(err, 1+depth))
+}
+
+// lazyLeafError is a leaf error whose message is formatted on demand.
+type lazyLeafError struct {
+	format string
+	args   []interface{}
+	once   sync.Once
+	msg    string
+}
+
+var (
+	_ error         = (*lazyLeafError)(nil)
+	_ fmt.Formatter = (*lazyLeafError)(nil)
+)
+
+func (l *lazyLeafError) Error() string {
+	l.once.Do(func() {
+		l.msg = fmt.Sprintf(l.format, l.args...)
+		// The args are not needed anymore; let them be collected.
+		l.args = nil
+	})
+
+	return l.msg
+}
+
+func (l *lazyLeafError) Format(s fmt.State, verb rune) { errbase.FormatError(l, s, verb) }
+func (l *lazyLeafError) SafeFormatError(p errbase.Printer) (next error) {
+	p.Print(l.Error())
+
+	return nil
+}
+
+// SafeDetails implements the errbase.SafeDetailer interface. Only the
+// format is reported: the args may contain PII.
+func (l *lazyLeafError) SafeDetails() []string {
+	return []string{l.format}
+}
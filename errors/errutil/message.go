@@ -28,6 +28,9 @@ func WithMessage(err error, message string) error {
 	if err == nil {
 		return nil
 	}
+	if isRedundantPrefix(err, message) {
+		return err
+	}
 
 	return &withPrefix{
 		cause:  err,
@@ -43,13 +46,109 @@ func WithMessagef(err error, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
+	prefix := fmt.Sprintf(format, args...)
+	if isRedundantPrefix(err, prefix) {
+		return err
+	}
 
 	return &withPrefix{
 		cause:  err,
-		prefix: fmt.Sprintf(format, args...),
+		prefix: prefix,
 	}
 }
 
+// skipRedundantPrefix, when set, makes WithMessage() and its variants
+// skip the prefixes equal to the message of their cause.
+var skipRedundantPrefix = struct {
+	errbase.Registry
+	enabled bool
+}{}
+
+// SetSkipRedundantPrefix controls whether WithMessage(), Wrap() and
+// their variants skip a message prefix that is exactly the current
+// message of the error they wrap, as with the common mistake
+// Wrap(err, err.Error()), which would otherwise print "msg: msg".
+// Wrap() still captures a stack trace. Disabled by default.
+func SetSkipRedundantPrefix(enabled bool) {
+	skipRedundantPrefix.Update(func() { skipRedundantPrefix.enabled = enabled })
+}
+
+// isRedundantPrefix returns true if prefix should be skipped as per
+// SetSkipRedundantPrefix().
+func isRedundantPrefix(err error, prefix string) bool {
+	var enabled bool
+	skipRedundantPrefix.Read(func() { enabled = skipRedundantPrefix.enabled })
+
+	return enabled && prefix != "" && prefix == err.Error()
+}
+
+// CollapsePrefixes merges the adjacent message prefixes in the chain
+// of err, as added by consecutive calls to WithMessage(), into a single
+// wrapper. The message of the error is unchanged: collapsing
+// WithMessage(WithMessage(err, "a"), "b") yields a single prefix "b: a".
+// Other layers in the chain are preserved.
+func CollapsePrefixes(err error) error {
+	if err == nil {
+		return nil
+	}
+	cause := errbase.UnwrapOnce(err)
+	if cause == nil {
+		return err
+	}
+	// Inner layers are collapsed first, so the cause has at most one
+	// prefix layer to merge with.
+	newCause := CollapsePrefixes(cause)
+
+	if outer, ok := err.(*withPrefix); ok {
+		if inner, ok := newCause.(*withPrefix); ok {
+			prefix := outer.prefix
+			switch {
+			case prefix == "":
+				prefix = inner.prefix
+			case inner.prefix != "":
+				prefix += ": " + inner.prefix
+			}
+
+			return &withPrefix{cause: inner.cause, prefix: prefix}
+		}
+	}
+	if newCause == cause {
+		return err
+	}
+
+	return errbase.Rewrap(err, newCause)
+}
+
+// OutermostPrefix returns the message prefix added by the outermost
+// call to WithMessage() or Wrap() in the chain of err, e.g. "b" for
+// Wrap(Wrap(err, "a"), "b"): the most recent context only, for a short
+// notification. Unlike Error(), the messages of the causes are not
+// included. The layers that do not contribute to the message, such as
+// stack traces, are skipped, and a layer that replaces the message of
+// its cause contributes its whole message. Returns "" if no layer adds
+// a prefix, or if the outermost one added an empty prefix. The layers
+// are visited as with errbase.Walk(), so the prefixes added inside the
+// branches of a multi-error are found too.
+func OutermostPrefix(err error) string {
+	var prefix string
+	errbase.Walk(err, func(c error) bool {
+		switch w := c.(type) {
+		case *withPrefix:
+			prefix = w.prefix
+
+			return false
+		case *withNewMessage:
+			prefix = w.message
+
+			return false
+		}
+
+		return true
+	})
+
+	return prefix
+}
+
 // withPrefix is like withMessage but the
 // message can contain redactable and non-redactable parts.
 type withPrefix struct {
@@ -62,7 +161,7 @@ func (l *withPrefix) Error() string {
 		return l.cause.Error()
 	}
 
-	return fmt.Sprintf("%s: %v", l.prefix, l.cause)
+	return l.prefix + ": " + l.cause.Error()
 }
 
 func (l *withPrefix) Cause() error  { return l.cause }
@@ -83,3 +182,9 @@ var (
 	_ error         = (*withPrefix)(nil)
 	_ fmt.Formatter = (*withPrefix)(nil)
 )
+
+func init() {
+	errbase.RegisterRewrapper((*withPrefix)(nil), func(err, newCause error) error {
+		return &withPrefix{cause: newCause, prefix: err.(*withPrefix).prefix}
+	})
+}
@@ -0,0 +1,42 @@
+package errutil
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// onCreate holds the hook registered with OnCreate, if any.
+var onCreate = struct {
+	errbase.Registry
+	fn func(err error)
+}{}
+
+// OnCreate registers fn to be called with every error created by
+// New(), Wrap() and their variants, as well as the Khan error
+// constructors, right after construction. It is called once per
+// error, with the value returned to the caller, e.g. the assertion
+// failure of AssertionFailedf(). This is meant for instrumentation,
+// e.g. to count errors in a metric. Only one hook
+// can be registered: a new call replaces the previous hook, and
+// OnCreate(nil), the default, removes it.
+//
+// The hook is called synchronously by the goroutine creating the
+// error, so it should be cheap. It must not create errors itself
+// with the constructors above, since that calls the hook again,
+// recursively, without end.
+func OnCreate(fn func(err error)) {
+	onCreate.Update(func() { onCreate.fn = fn })
+}
+
+// NotifyCreated calls the hook registered with OnCreate(), if any,
+// with err, and returns err. It is meant to be called by the error
+// constructors of this module. Nothing happens if err is nil.
+func NotifyCreated(err error) error {
+	if err == nil {
+		return nil
+	}
+	var fn func(err error)
+	onCreate.Read(func() { fn = onCreate.fn })
+	if fn != nil {
+		fn(err)
+	}
+
+	return err
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/StevenACoffman/anotherr/errors/errbase"
+	"github.com/StevenACoffman/anotherr/errors/secondary"
 	"github.com/StevenACoffman/anotherr/errors/withstack"
 )
 
@@ -44,7 +45,7 @@ func New(msg string) error {
 func NewWithDepth(depth int, msg string) error {
 	err := error(&leafError{msg})
 
-	return withstack.WithStackDepth(err, 1+depth)
+	return NotifyCreated(withstack.WithStackDepth(err, 1+depth))
 }
 
 // Newf creates an error with a formatted error message.
@@ -64,6 +65,12 @@ func Newf(format string, args ...interface{}) error {
 // trace is configurable.
 // See the doc of `New()` for more details.
 func NewWithDepthf(depth int, format string, args ...interface{}) error {
+	return NotifyCreated(newWithDepthf(1+depth, format, args...))
+}
+
+// newWithDepthf is like NewWithDepthf() without calling the hook of
+// OnCreate(), for the constructors that wrap the result further.
+func newWithDepthf(depth int, format string, args ...interface{}) error {
 	wrappedErr := fmt.Errorf(format, args...)
 
 	return withstack.WithStackDepth(wrappedErr, 1+depth)
@@ -91,19 +98,22 @@ func Wrap(err error, msg string) error {
 // trace is configurable.
 // The the doc of `Wrap()` for more details.
 func WrapWithDepth(depth int, err error, msg string) error {
-	if err == nil {
+	if err == nil || errbase.IsTypedNil(err) {
 		return nil
 	}
 	if msg != "" {
 		err = WithMessage(err, msg)
 	}
 
-	return withstack.WithStackDepth(err, depth+1)
+	return NotifyCreated(withstack.WithStackDepth(err, depth+1))
 }
 
 // Wrapf wraps an error with a formatted message prefix. A stack
 // trace is also retained. If the format is empty, no prefix is added,
 // but the extra arguments are still processed for reportable strings.
+// If there are no arguments, the format is used literally as the
+// prefix, like with Wrap(): Wrapf(err, "50% off") keeps the percent,
+// and so does Wrapf(err, "100%% done"), which prints "100%% done".
 //
 // Note: the format string is assumed to not contain
 // PII and is included in Sentry reports.
@@ -124,7 +134,13 @@ func Wrapf(err error, format string, args ...interface{}) error {
 // trace is configurable.
 // The the doc of `Wrapf()` for more details.
 func WrapWithDepthf(depth int, err error, format string, args ...interface{}) error {
-	if err == nil {
+	return NotifyCreated(wrapWithDepthf(depth+1, err, format, args...))
+}
+
+// wrapWithDepthf is like WrapWithDepthf() without calling the hook of
+// OnCreate(), for the constructors that wrap the result further.
+func wrapWithDepthf(depth int, err error, format string, args ...interface{}) error {
+	if err == nil || errbase.IsTypedNil(err) {
 		return nil
 	}
 	var errRefs []error
@@ -133,9 +149,19 @@ func WrapWithDepthf(depth int, err error, format string, args ...interface{}) er
 			errRefs = append(errRefs, e)
 		}
 	}
-	if format != "" || len(args) > 0 {
+	switch {
+	case len(args) == 0:
+		// Without args, the format is taken literally, like the
+		// message of Wrap().
+		if format != "" {
+			err = WithMessage(err, format)
+		}
+	default:
 		err = WithMessagef(err, format, args...)
 	}
+	for _, e := range errRefs {
+		err = secondary.WithSecondaryError(err, e)
+	}
 
 	return withstack.WithStackDepth(err, depth+1)
 }
@@ -192,3 +218,9 @@ func (l *leafError) SafeFormatError(p errbase.Printer) (next error) {
 func (l *leafError) SafeDetails() []string {
 	return []string{l.msg}
 }
+
+func init() {
+	errbase.RegisterRewrapper((*withNewMessage)(nil), func(err, newCause error) error {
+		return &withNewMessage{cause: newCause, message: err.(*withNewMessage).message}
+	})
+}
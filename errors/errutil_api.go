@@ -1,6 +1,8 @@
 package errors
 
 import (
+	"reflect"
+
 	"github.com/StevenACoffman/anotherr/errors/errbase"
 	"github.com/StevenACoffman/anotherr/errors/errutil"
 )
@@ -53,12 +55,81 @@ func Errorf(format string, args ...interface{}) error {
 	return errutil.NewWithDepthf(1, format, args...)
 }
 
+// NewLazyf is like Newf, except that the message is only formatted
+// the first time it is needed, and then memoized. The args must not be
+// modified after the call.
+// See the doc of errutil.NewLazyf() for more details.
+func NewLazyf(format string, args ...interface{}) error {
+	return errutil.NewLazyWithDepthf(1, format, args...)
+}
+
+// OnCreate registers fn to be called with every error created by
+// New(), Wrap() and their variants, as well as the Khan error
+// constructors such as NotFound(), right after construction, e.g. to
+// count errors in a metric. A new call replaces the previous hook;
+// OnCreate(nil), the default, removes it.
+//
+// The hook must not create errors with these constructors itself,
+// since that would call it again recursively without end.
+// See errutil.OnCreate() for details.
+func OnCreate(fn func(err error)) { errutil.OnCreate(fn) }
+
 // Cause aliases UnwrapAll() for compatibility with github.com/pkg/errors.
 func Cause(err error) error { return errbase.UnwrapAll(err) }
 
+// ShareRootCause returns true if a and b have the same root cause
+// (see Cause), despite being wrapped differently. The root causes are
+// the same if they are the same error, or if they have the same type
+// and message, as is the case e.g. of an error that went through
+// ToJSON() and FromJSON(). This is useful to group related failures.
+// Returns false if either error is nil.
+func ShareRootCause(a, b error) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	ra, rb := errbase.UnwrapAll(a), errbase.UnwrapAll(b)
+	if reflect.TypeOf(ra).Comparable() && equal(ra, rb) {
+		return true
+	}
+
+	return errbase.TypeName(ra) == errbase.TypeName(rb) && ra.Error() == rb.Error()
+}
+
 // Unwrap aliases UnwrapOnce() for compatibility with xerrors.
 func Unwrap(err error) error { return errbase.UnwrapOnce(err) }
 
+// UnwrapN returns the error found n levels down the chain of causes
+// of err, or nil if the chain is shorter than that. UnwrapN(err, 0)
+// returns err itself. This is useful to check the structure of an
+// error in tests.
+func UnwrapN(err error, n int) error { return errbase.UnwrapN(err, n) }
+
+// IsTypedNil returns true if err is a non-nil interface holding a nil
+// pointer, e.g. a nil *MyError returned as an error. Wrap(),
+// WrapWithFields(), KhanWrap() and the kind constructors treat such an
+// error like nil, and return nil.
+// See errbase.IsTypedNil() for details.
+func IsTypedNil(err error) bool { return errbase.IsTypedNil(err) }
+
+// Depth returns the number of layers in the chain of causes of err,
+// including err itself. The depth of a nil error is 0.
+//
+// This is also what is printed when formatting an error with %d.
+func Depth(err error) int { return errbase.Depth(err) }
+
+// ListWrappers returns the name of the Go type of each layer in the
+// chain of causes of err, from the outermost to the innermost, e.g.
+// ["*errutil.withPrefix", "*errors.withFields", "*errutil.leafError"].
+// This is a lightweight way to see how an error was constructed.
+func ListWrappers(err error) []string {
+	var res []string
+	for c := err; c != nil; c = errbase.UnwrapOnce(c) {
+		res = append(res, errbase.TypeName(c))
+	}
+
+	return res
+}
+
 // Wrapper is the type of an error wrapper.
 type Wrapper interface {
 	Unwrap() error
@@ -81,6 +152,26 @@ func WithMessagef(err error, format string, args ...interface{}) error {
 	return errutil.WithMessagef(err, format, args...)
 }
 
+// CollapsePrefixes merges the adjacent message prefixes in the chain
+// of err, as added by consecutive calls to WithMessage(), into a single
+// wrapper. The message of the error is unchanged: collapsing
+// WithMessage(WithMessage(err, "a"), "b") yields a single prefix "b: a".
+// Other layers in the chain are preserved.
+func CollapsePrefixes(err error) error { return errutil.CollapsePrefixes(err) }
+
+// SetSkipRedundantPrefix controls whether WithMessage(), Wrap() and
+// their variants skip a message prefix equal to the current message of
+// the error they wrap, e.g. with Wrap(err, err.Error()). Disabled by
+// default.
+// See errutil.SetSkipRedundantPrefix() for details.
+func SetSkipRedundantPrefix(enabled bool) { errutil.SetSkipRedundantPrefix(enabled) }
+
+// OutermostPrefix returns the message prefix added by the outermost
+// call to WithMessage() or Wrap() in the chain of err, e.g. "b" for
+// Wrap(Wrap(err, "a"), "b"), or "" if there is none. Unlike Error(),
+// the messages of the causes are not included.
+func OutermostPrefix(err error) string { return errutil.OutermostPrefix(err) }
+
 // Wrap wraps an error with a message prefix.
 // A stack trace is retained.
 //
@@ -104,9 +195,40 @@ func WrapWithDepth(depth int, err error, msg string) error {
 	return errutil.WrapWithDepth(depth+1, err, msg)
 }
 
+// WrapIf is like Wrap when cond is true, and returns err unchanged
+// otherwise. This keeps error handling linear where the alternative is
+// `if cond { err = errors.Wrap(err, msg) }`.
+// If err is nil, WrapIf returns nil.
+func WrapIf(cond bool, err error, msg string) error {
+	if !cond {
+		return err
+	}
+
+	return errutil.WrapWithDepth(1, err, msg)
+}
+
+// WrapEach wraps each error in errs with the message prefix msg, like
+// Wrap, e.g. to annotate the results of fanned-out work uniformly
+// before aggregating them. The nil entries stay nil. The result is a
+// new slice.
+func WrapEach(errs []error, msg string) []error {
+	if errs == nil {
+		return nil
+	}
+	res := make([]error, len(errs))
+	for i, err := range errs {
+		res[i] = errutil.WrapWithDepth(1, err, msg)
+	}
+
+	return res
+}
+
 // Wrapf wraps an error with a formatted message prefix. A stack
 // trace is also retained. If the format is empty, no prefix is added,
 // but the extra arguments are still processed for reportable strings.
+// If there are no arguments, the format is used literally as the
+// prefix, like with Wrap(): Wrapf(err, "50% off") keeps the percent,
+// and so does Wrapf(err, "100%% done"), which prints "100%% done".
 //
 // Note: the format string is assumed to not contain
 // PII and is included in Sentry reports.
@@ -130,6 +252,36 @@ func WrapWithDepthf(depth int, err error, format string, args ...interface{}) er
 	return errutil.WrapWithDepthf(depth+1, err, format, args...)
 }
 
+// AssertionFailedf creates an internal error.
+//
+// Detail is shown:
+// - stack trace via `errors.GetSafeDetails()`, without the message.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func AssertionFailedf(format string, args ...interface{}) error {
+	return errutil.AssertionFailedWithDepthf(1, format, args...)
+}
+
+// AssertionFailedWithDepthf creates an internal error
+// with a stack trace collected at the specified depth.
+// See the doc of `AssertionFailedf()` for more details.
+func AssertionFailedWithDepthf(depth int, format string, args ...interface{}) error {
+	return errutil.AssertionFailedWithDepthf(depth+1, format, args...)
+}
+
+// NewAssertionErrorWithWrappedErrf wraps an error and turns it into
+// an assertion error. The original error remains visible to Is() and
+// As() through the chain.
+//
+// Detail is shown:
+// - stack trace and formatted message via `errors.GetSafeDetails()`,
+// followed by the safe details of the original error.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func NewAssertionErrorWithWrappedErrf(origErr error, format string, args ...interface{}) error {
+	return errutil.NewAssertionErrorWithWrappedErrDepthf(1, origErr, format, args...)
+}
+
 // As finds the first error in err's chain that matches the type to which
 // target points, and if so, sets the target to its value and returns true.
 // An error matches a type if it is assignable to the target type, or if it
@@ -145,6 +297,29 @@ func WrapWithDepthf(depth int, err error, format string, args ...interface{}) er
 // - if it detects an API use error, its panic object is a valid error.
 func As(err error, target interface{}) bool { return errutil.As(err, target) }
 
+// AsAll is like As, but finds all the errors in err's chain that match
+// the element type of the slice to which target points, and appends
+// them to it. It descends into the branches of multi-errors.
+// See the doc of errutil.AsAll() for details.
+func AsAll(err error, target interface{}) bool { return errutil.AsAll(err, target) }
+
+// Find returns the first error in the chain of err, starting with err
+// itself, for which pred returns true, or nil if there is none. This
+// is the building block for custom searches through the causes of an
+// error, e.g. for a layer whose message contains some text.
+func Find(err error, pred func(error) bool) error {
+	var res error
+	errbase.Walk(err, func(c error) bool {
+		if pred(c) {
+			res = c
+		}
+
+		return res == nil
+	})
+
+	return res
+}
+
 // Is determines whether one of the causes of the given error or any
 // of its causes is equivalent to some reference error.
 //
@@ -177,6 +352,9 @@ func As(err error, target interface{}) bool { return errutil.As(err, target) }
 // Note: if any of the error types has been migrated from a previous
 // package location or a different type, ensure that
 // RegisterTypeMigration() was called prior to Is().
+//
+// The branches of multi-errors, e.g. those built with the errors.Join()
+// of the standard library, are searched in order.
 func Is(err, reference error) bool {
 	if reference == nil {
 		return err == nil
@@ -184,15 +362,13 @@ func Is(err, reference error) bool {
 
 	// Direct reference comparison is the fastest, and most
 	// likely to be true, so do this first.
-	for c := err; c != nil; c = errbase.UnwrapOnce(c) {
-		if equal(c, reference) {
-			return true
-		}
+	found := !errbase.Walk(err, func(c error) bool {
 		// Compatibility with std go errors: if the error object itself
 		// implements Is(), try to use that.
-		if tryDelegateToIsMethod(c, reference) {
-			return true
-		}
+		return !equal(c, reference) && !tryDelegateToIsMethod(c, reference)
+	})
+	if found {
+		return true
 	}
 
 	if err == nil {
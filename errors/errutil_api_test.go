@@ -0,0 +1,372 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOutermostPrefixThroughMultiError(t *testing.T) {
+	err := WithHint(multiError{WithHint(Wrap(New("a"), "loading"), "h"), New("b")}, "h")
+
+	if got := OutermostPrefix(err); got != "loading" {
+		t.Errorf("expected %q, got %q", "loading", got)
+	}
+}
+
+func TestOutermostPrefix(t *testing.T) {
+	err := Wrap(Wrap(stderrors.New("boom"), "loading user"), "handling request")
+
+	if got := OutermostPrefix(err); got != "handling request" {
+		t.Errorf("expected %q, got %q", "handling request", got)
+	}
+	if got := OutermostPrefix(WithHint(err, "retry")); got != "handling request" {
+		t.Errorf("expected the layers without a prefix skipped, got %q", got)
+	}
+	if got := OutermostPrefix(New("boom")); got != "" {
+		t.Errorf("expected no prefix, got %q", got)
+	}
+}
+
+func TestWrapfWithoutArgs(t *testing.T) {
+	for _, tc := range []struct {
+		// format is a variable, so that vet does not check it.
+		format string
+		want   string
+	}{
+		{"50% off", "50% off: x"},
+		{"100%% done", "100%% done: x"},
+		{"rate 100%%!", "rate 100%%!: x"},
+		{"plain", "plain: x"},
+		{"", "x"},
+	} {
+		if got := Wrapf(New("x"), tc.format).Error(); got != tc.want {
+			t.Errorf("Wrapf(%q): expected %q, got %q", tc.format, tc.want, got)
+		}
+	}
+}
+
+type validationError struct{ field string }
+
+func (e *validationError) Error() string { return "invalid " + e.field }
+
+func TestAsAllCollectsEveryMatch(t *testing.T) {
+	name := &validationError{"name"}
+	email := &validationError{"email"}
+	err := Wrap(multiError{Wrap(name, "checking"), New("other"), email}, "saving")
+
+	var errs []*validationError
+	if !AsAll(err, &errs) {
+		t.Fatalf("expected a match")
+	}
+	if len(errs) != 2 || errs[0] != name || errs[1] != email {
+		t.Errorf("expected [name email], got %v", errs)
+	}
+
+	var none []*validationError
+	if AsAll(New("x"), &none) || len(none) != 0 {
+		t.Errorf("expected no match, got %v", none)
+	}
+}
+
+func TestCollapsePrefixes(t *testing.T) {
+	root := stderrors.New("root")
+	err := WithMessage(WithMessage(root, "a"), "b")
+	collapsed := CollapsePrefixes(err)
+
+	if got := collapsed.Error(); got != "b: a: root" {
+		t.Errorf("expected the message to be unchanged, got %q", got)
+	}
+	if got := Depth(collapsed); got != 2 {
+		t.Errorf("expected a single prefix layer around the root, got %d layers", got)
+	}
+	if got := OutermostPrefix(collapsed); got != "b: a" {
+		t.Errorf("expected the prefix %q, got %q", "b: a", got)
+	}
+	if Unwrap(collapsed) != root {
+		t.Errorf("expected the root as the cause of the collapsed prefix")
+	}
+}
+
+func TestUnwrapN(t *testing.T) {
+	root := stderrors.New("root")
+	second := WithMessage(root, "a")
+	err := WithHint(WithMessage(second, "b"), "h")
+
+	for n, want := range []error{err, Unwrap(err), second, root, nil, nil} {
+		if got := UnwrapN(err, n); got != want {
+			t.Errorf("UnwrapN(err, %d): expected %v, got %v", n, want, got)
+		}
+	}
+}
+
+func TestListWrappers(t *testing.T) {
+	err := Wrap(WrapWithFields(NotFound("no user"), Fields{"user": 42}), "loading")
+
+	want := []string{
+		"*withstack.withStack",
+		"*errutil.withPrefix",
+		"*errors.withFields",
+		"*errors.khanError",
+		"errors.errorKind",
+	}
+	if got := ListWrappers(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := ListWrappers(nil); got != nil {
+		t.Errorf("expected nil, got %q", got)
+	}
+}
+
+func TestShareRootCause(t *testing.T) {
+	sentinel := stderrors.New("connection refused")
+	a := Wrap(WithHint(sentinel, "h"), "loading")
+	b := NotFound(WrapWithFields(sentinel, Fields{"user": 42}))
+
+	if !ShareRootCause(a, b) {
+		t.Errorf("expected a shared root cause")
+	}
+	if ShareRootCause(a, Wrap(stderrors.New("timeout"), "loading")) {
+		t.Errorf("expected different root causes")
+	}
+	if ShareRootCause(a, nil) {
+		t.Errorf("expected false with a nil error")
+	}
+}
+
+func TestOnCreate(t *testing.T) {
+	// Created before the hook is registered, so that only KhanWrap()
+	// calls it, below.
+	inner := NotFound("i")
+	var created []error
+	OnCreate(func(err error) { created = append(created, err) })
+	defer OnCreate(nil)
+
+	errs := []error{
+		New("a"),
+		Newf("b %d", 1),
+		Wrap(stderrors.New("c"), "wrapped"),
+		Wrapf(stderrors.New("d"), "wrapped %d", 2),
+		NotFound("e"),
+		Internal(stderrors.New("f")),
+		AssertionFailedf("g %d", 3),
+		NewAssertionErrorWithWrappedErrf(stderrors.New("h"), "wrapped"),
+		KhanWrap(inner, "key", "value", stderrors.New("j")),
+	}
+	if len(created) != len(errs) {
+		t.Fatalf("expected %d calls, got %d", len(errs), len(created))
+	}
+	for i, err := range errs {
+		if created[i] != err {
+			t.Errorf("call %d: expected %v, got %v", i, err, created[i])
+		}
+	}
+
+	OnCreate(nil)
+	_ = New("g")
+	if len(created) != len(errs) {
+		t.Errorf("expected no call after removing the hook")
+	}
+}
+
+func TestWrapIf(t *testing.T) {
+	root := stderrors.New("boom")
+
+	if got := WrapIf(true, root, "loading"); got == nil || got.Error() != "loading: boom" {
+		t.Errorf("expected a wrapped error, got %v", got)
+	}
+	if got := WrapIf(false, root, "loading"); got != root {
+		t.Errorf("expected the error unchanged, got %v", got)
+	}
+	if got := WrapIf(true, nil, "loading"); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+	if got := WrapIf(false, nil, "loading"); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
+
+// maskedError hides the message of its cause.
+type maskedError struct{ cause error }
+
+func (e *maskedError) Error() string { return "masked" }
+func (e *maskedError) Unwrap() error { return e.cause }
+
+func TestFind(t *testing.T) {
+	leaf := stderrors.New("disk full")
+	inner := Wrap(leaf, "write")
+	err := Wrap(&maskedError{inner}, "save")
+
+	contains := func(s string) func(error) bool {
+		return func(err error) bool { return strings.Contains(err.Error(), s) }
+	}
+	if got := Find(err, contains("save")); got != err {
+		t.Errorf("expected the outermost layer, got %v", got)
+	}
+	if got := Find(err, contains("disk full")); got != inner {
+		t.Errorf("expected the first layer below the masked one, got %v", got)
+	}
+	if got := Find(err, contains("unknown")); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+	if got := Find(nil, contains("")); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
+
+func TestWrapEach(t *testing.T) {
+	a, b := stderrors.New("a"), stderrors.New("b")
+
+	got := WrapEach([]error{a, nil, b}, "fetching")
+	if len(got) != 3 {
+		t.Fatalf("expected 3 errors, got %d", len(got))
+	}
+	if got[1] != nil {
+		t.Errorf("expected nil to stay nil, got %v", got[1])
+	}
+	for i, want := range map[int]error{0: a, 2: b} {
+		if msg := "fetching: " + want.Error(); got[i].Error() != msg {
+			t.Errorf("%d: expected %q, got %q", i, msg, got[i].Error())
+		}
+		if !Is(got[i], want) {
+			t.Errorf("%d: expected the original error as cause", i)
+		}
+	}
+
+	withFields := WrapEachWithFields([]error{a, nil}, Fields{"shard": 3})
+	if shard, _ := GetField(withFields[0], "shard"); shard != 3 {
+		t.Errorf("expected the field on the error, got %v", shard)
+	}
+	if withFields[1] != nil {
+		t.Errorf("expected nil to stay nil, got %v", withFields[1])
+	}
+
+	if WrapEach(nil, "fetching") != nil || WrapEachWithFields(nil, Fields{}) != nil {
+		t.Error("expected nil for a nil slice")
+	}
+}
+
+// countingStringer counts the calls to its String method.
+type countingStringer struct{ calls int }
+
+func (s *countingStringer) String() string {
+	s.calls++
+
+	return "bob"
+}
+
+func TestNewLazyf(t *testing.T) {
+	arg := &countingStringer{}
+	err := NewLazyf("no user %s (%d)", arg, 42)
+	if arg.calls != 0 {
+		t.Errorf("expected no formatting at construction, got %d calls", arg.calls)
+	}
+
+	for i := 0; i < 3; i++ {
+		if got := err.Error(); got != "no user bob (42)" {
+			t.Errorf("expected %q, got %q", "no user bob (42)", got)
+		}
+	}
+	_ = fmt.Sprintf("%+v", err)
+	if arg.calls != 1 {
+		t.Errorf("expected the message formatted once, got %d calls", arg.calls)
+	}
+}
+
+func BenchmarkNewLazyf(b *testing.B) {
+	b.Run("Newf", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = Newf("no user %s (%d)", "bob", i)
+		}
+	})
+	b.Run("NewLazyf", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = NewLazyf("no user %s (%d)", "bob", i)
+		}
+	})
+}
+
+func TestSetSkipRedundantPrefix(t *testing.T) {
+	root := stderrors.New("connection refused")
+
+	if got := Wrap(root, root.Error()).Error(); got != "connection refused: connection refused" {
+		t.Errorf("expected the prefix kept by default, got %q", got)
+	}
+
+	SetSkipRedundantPrefix(true)
+	defer SetSkipRedundantPrefix(false)
+	err := Wrap(root, root.Error())
+	if got := err.Error(); got != "connection refused" {
+		t.Errorf("expected the redundant prefix skipped, got %q", got)
+	}
+	if StackCount(err) != 1 {
+		t.Errorf("expected a stack trace, got %d", StackCount(err))
+	}
+	if got := WithMessage(root, root.Error()).Error(); got != "connection refused" {
+		t.Errorf("expected the redundant prefix skipped by WithMessage, got %q", got)
+	}
+	if got := Wrap(root, "dialing").Error(); got != "dialing: connection refused" {
+		t.Errorf("expected other prefixes kept, got %q", got)
+	}
+}
+
+// typedNilError is returned as a nil pointer by failingCheck.
+type typedNilError struct{}
+
+func (*typedNilError) Error() string { return "typed nil" }
+
+// failingCheck reproduces the classic mistake of returning a nil
+// pointer as an error, which is not equal to nil.
+func failingCheck() error {
+	var e *typedNilError
+
+	return e
+}
+
+func TestIsTypedNil(t *testing.T) {
+	err := failingCheck()
+	if err == nil {
+		t.Fatal("expected a non-nil interface")
+	}
+	if !IsTypedNil(err) {
+		t.Error("expected a typed nil")
+	}
+	if IsTypedNil(nil) || IsTypedNil(New("boom")) {
+		t.Error("expected nil and a real error not to be typed nils")
+	}
+
+	for name, wrapped := range map[string]error{
+		"Wrap":                 Wrap(err, "checking"),
+		"Wrapf":                Wrapf(err, "checking %d", 1),
+		"WrapWithFields":       WrapWithFields(err, Fields{"user": 42}),
+		"NotFound":             NotFound(err),
+		"Internal":             Internal(err, Fields{"user": 42}),
+		"KhanWrap":             KhanWrap(err, "user", 42),
+		"InternalWithFields":   InternalWithFields(err, "checking", Fields{"user": 42}),
+		"WrapWithFieldsMerged": WrapWithFieldsMerged(err, Fields{"user": 42}),
+	} {
+		if wrapped != nil {
+			t.Errorf("%s: expected nil for a typed nil, got %T", name, wrapped)
+		}
+	}
+}
+
+func TestNewLazyfSafeDetails(t *testing.T) {
+	err := NewLazyf("no user %s", "bob@example.com")
+
+	// Before and after the message is formatted.
+	for i := 0; i < 2; i++ {
+		details := GetSafeDetails(err)
+		if !containsString(details, "no user %s") {
+			t.Errorf("expected the format in the safe details, got %q", details)
+		}
+		for _, d := range details {
+			if strings.Contains(d, "bob@example.com") {
+				t.Errorf("expected no args in the safe details, got %q", details)
+			}
+		}
+		_ = err.Error()
+	}
+}
@@ -0,0 +1,41 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// exitCodeField is the field holding the exit code set by WithExitCode.
+const exitCodeField = "exit_code"
+
+// WithExitCode annotates err with the exit code that a command-line
+// tool should use when it terminates because of err. The code is
+// stored as a field. If err is nil, WithExitCode returns nil.
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+
+	return WrapWithFieldsAndDepth(err, Fields{exitCodeField: code}, 1)
+}
+
+// GetExitCode returns the exit code to use for err, e.g.
+//
+//	os.Exit(errors.GetExitCode(err))
+//
+// If several exit codes were set in the chain of err, the innermost
+// one wins. If none was set, the result is 1, or 0 if err is nil. The
+// exit code is also found after a round trip through ToJSON and
+// FromJSON.
+func GetExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	code := 1
+	errbase.Walk(err, func(c error) bool {
+		if v, ok := intFieldValue(layerFields(c)[exitCodeField]); ok {
+			code = v
+		}
+
+		return true
+	})
+
+	return code
+}
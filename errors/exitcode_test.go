@@ -0,0 +1,30 @@
+package errors
+
+import "testing"
+
+func TestGetExitCode(t *testing.T) {
+	err := Wrap(WithExitCode(WithExitCode(New("usage"), 2), 3), "parsing flags")
+	if got := GetExitCode(err); got != 2 {
+		t.Errorf("expected the innermost exit code 2, got %d", got)
+	}
+	if got := GetExitCode(New("boom")); got != 1 {
+		t.Errorf("expected the default exit code 1, got %d", got)
+	}
+	if got := GetExitCode(nil); got != 0 {
+		t.Errorf("expected 0 for nil, got %d", got)
+	}
+}
+
+func TestGetExitCodeAfterJSON(t *testing.T) {
+	data, encErr := ToJSON(Wrap(WithExitCode(New("usage"), 2), "parsing flags"))
+	if encErr != nil {
+		t.Fatal(encErr)
+	}
+	decoded, decErr := FromJSON(data)
+	if decErr != nil {
+		t.Fatal(decErr)
+	}
+	if got := GetExitCode(decoded); got != 2 {
+		t.Errorf("expected exit code 2 after decoding, got %d", got)
+	}
+}
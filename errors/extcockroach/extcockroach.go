@@ -0,0 +1,204 @@
+// Package extcockroach converts errors between this module and
+// github.com/cockroachdb/errors, from which it is forked, for the
+// teams migrating from one to the other.
+//
+// The conversions preserve the message of the error, its innermost
+// stack trace, its hints, its kind and its fields:
+//   - kinds become marks (see the cockroachdb/errors Mark()), so that
+//     the kind of a converted error can be tested with Is();
+//   - fields become context tags (see WithContextTags()), which only
+//     keep the rendering of their values: converted back, the values
+//     are strings.
+//
+// The structure of the chain of causes is not preserved: the result of
+// a conversion is a new chain with one layer for each of the above.
+package extcockroach
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+
+	"github.com/StevenACoffman/anotherr/errors"
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+	crerrors "github.com/cockroachdb/errors"
+	crerrbase "github.com/cockroachdb/errors/errbase"
+	"github.com/cockroachdb/logtags"
+)
+
+// kinds are the kinds preserved by the conversions, with the
+// constructor of the Khan errors of each kind and the mark that
+// represents it on the cockroachdb/errors side.
+var kinds = []struct {
+	kind error
+	ctor func(args ...interface{}) error
+	mark error
+}{
+	{kind: errors.NotFoundKind, ctor: errors.NotFound},
+	{kind: errors.InvalidInputKind, ctor: errors.InvalidInput},
+	{kind: errors.NotAllowedKind, ctor: errors.NotAllowed},
+	{kind: errors.UnauthorizedKind, ctor: errors.Unauthorized},
+	{kind: errors.InternalKind, ctor: errors.Internal},
+	{kind: errors.GraphqlResponseKind, ctor: errors.GraphqlResponse},
+	{kind: errors.NotImplementedKind, ctor: errors.NotImplemented},
+	{kind: errors.TransientKhanServiceKind, ctor: errors.TransientKhanService},
+	{kind: errors.KhanServiceKind, ctor: errors.KhanService},
+	{kind: errors.ServiceKind, ctor: errors.Service},
+	{kind: errors.TransientServiceKind, ctor: errors.TransientService},
+}
+
+const (
+	// codeTag is the context tag holding the code of an error (see
+	// errors.WithCode).
+	codeTag = "code"
+	// detailsField is the field holding the details of an error of
+	// cockroachdb/errors (see its WithDetail()).
+	detailsField = "details"
+)
+
+func init() {
+	for i := range kinds {
+		kinds[i].mark = crerrors.Newf("kind: %s", kinds[i].kind)
+	}
+}
+
+// ToCockroach converts err to an error of github.com/cockroachdb/errors.
+// The code of err (see errors.WithCode), which has no equivalent
+// there, is kept as the "code" context tag. Returns nil if err is nil.
+func ToCockroach(err error) error {
+	if err == nil {
+		return nil
+	}
+	var res error = stderrors.New(err.Error())
+	var st errbase.StackTrace
+	errbase.Walk(err, func(c error) bool {
+		if p, ok := c.(errbase.StackTraceProvider); ok && len(p.StackTrace()) != 0 {
+			st = p.StackTrace()
+		}
+
+		return true
+	})
+	if st != nil {
+		res = &withCockroachStack{cause: res, stack: st}
+	}
+	for _, h := range errors.GetAllHints(err) {
+		res = crerrors.WithHint(res, h)
+	}
+	if kind, ok := errors.KindOf(err); ok {
+		for _, k := range kinds {
+			if error(kind) == k.kind {
+				res = crerrors.Mark(res, k.mark)
+
+				break
+			}
+		}
+	}
+	fields := errors.GetFields(err)
+	if code, ok := errors.GetCode(err); ok {
+		// There are no codes in cockroachdb/errors.
+		if fields == nil {
+			fields = errors.Fields{}
+		}
+		fields[codeTag] = code
+	}
+	if len(fields) != 0 {
+		ctx := context.Background()
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ctx = logtags.AddTag(ctx, k, fields[k])
+		}
+		res = crerrors.WithContextTags(res, ctx)
+	}
+
+	return res
+}
+
+// FromCockroach converts err, an error of github.com/cockroachdb/errors,
+// to an error of this module: a Khan error if err has the mark of a
+// kind (see ToCockroach), with its context tags as fields. The
+// details of err, which have no equivalent here, are kept as the
+// "details" field; the other features of cockroachdb/errors, e.g.
+// telemetry keys, are not converted. Returns nil if err is nil.
+func FromCockroach(err error) error {
+	if err == nil {
+		return nil
+	}
+	var res error = stderrors.New(err.Error())
+	var st errbase.StackTrace
+	for c := err; c != nil; c = crerrbase.UnwrapOnce(c) {
+		if p, ok := c.(crerrbase.StackTraceProvider); ok && len(p.StackTrace()) != 0 {
+			st = p.StackTrace()
+		}
+	}
+	if st != nil {
+		res = &withStack{cause: res, stack: st}
+	}
+	for _, h := range crerrors.GetAllHints(err) {
+		res = errors.WithHint(res, h)
+	}
+	fields := errors.Fields{}
+	for _, buf := range crerrors.GetContextTags(err) {
+		for _, tag := range buf.Get() {
+			fields[tag.Key()] = tag.Value()
+		}
+	}
+	// There are no details in this module.
+	if details := crerrors.GetAllDetails(err); len(details) != 0 {
+		fields[detailsField] = details
+	}
+	code, hasCode := fields[codeTag].(string)
+	delete(fields, codeTag)
+	if len(fields) == 0 {
+		fields = nil
+	}
+	kindFound := false
+	for _, k := range kinds {
+		if crerrors.Is(err, k.mark) {
+			res, kindFound = k.ctor(res, fields), true
+
+			break
+		}
+	}
+	if !kindFound && fields != nil {
+		res = errors.WrapWithFieldsAndDepth(res, fields, 1)
+	}
+	if hasCode {
+		res = errors.WithCode(res, code)
+	}
+
+	return res
+}
+
+// withStack carries a stack trace converted from cockroachdb/errors.
+type withStack struct {
+	cause error
+	stack errbase.StackTrace
+}
+
+func (w *withStack) Error() string                         { return w.cause.Error() }
+func (w *withStack) Cause() error                          { return w.cause }
+func (w *withStack) Unwrap() error                         { return w.cause }
+func (w *withStack) StackTrace() errbase.StackTrace        { return w.stack }
+func (w *withStack) Format(s fmt.State, verb rune)         { errbase.FormatError(w, s, verb) }
+func (w *withStack) SafeFormatError(errbase.Printer) error { return w.cause }
+
+// withCockroachStack carries a stack trace converted to
+// cockroachdb/errors.
+type withCockroachStack struct {
+	cause error
+	stack crerrbase.StackTrace
+}
+
+func (w *withCockroachStack) Error() string                    { return w.cause.Error() }
+func (w *withCockroachStack) Cause() error                     { return w.cause }
+func (w *withCockroachStack) Unwrap() error                    { return w.cause }
+func (w *withCockroachStack) StackTrace() crerrbase.StackTrace { return w.stack }
+func (w *withCockroachStack) Format(s fmt.State, verb rune) {
+	crerrbase.FormatError(w, s, verb)
+}
+func (w *withCockroachStack) SafeFormatError(crerrbase.Printer) error { return w.cause }
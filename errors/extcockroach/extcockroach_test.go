@@ -0,0 +1,78 @@
+package extcockroach
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/StevenACoffman/anotherr/errors"
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+	crerrors "github.com/cockroachdb/errors"
+	crerrbase "github.com/cockroachdb/errors/errbase"
+)
+
+// innermostStack returns the innermost stack trace in the chain of
+// err, walked with unwrap.
+func innermostStack(err error, unwrap func(error) error) []uintptr {
+	var res []uintptr
+	for c := err; c != nil; c = unwrap(c) {
+		switch p := c.(type) {
+		case errbase.StackTraceProvider:
+			if st := p.StackTrace(); len(st) != 0 {
+				res = res[:0]
+				for _, f := range st {
+					res = append(res, uintptr(f))
+				}
+			}
+		case crerrbase.StackTraceProvider:
+			if st := p.StackTrace(); len(st) != 0 {
+				res = res[:0]
+				for _, f := range st {
+					res = append(res, uintptr(f))
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+func TestRoundTrip(t *testing.T) {
+	err := errors.WithHint(errors.NotFound("no user", errors.Fields{"user": 42}), "check the user id")
+	stack := innermostStack(err, errbase.UnwrapOnce)
+	if len(stack) == 0 {
+		t.Fatal("expected a stack trace on the original error")
+	}
+
+	cr := ToCockroach(err)
+	if got := crerrors.GetAllHints(cr); !reflect.DeepEqual(got, []string{"check the user id"}) {
+		t.Errorf("expected the hint on the cockroachdb error, got %q", got)
+	}
+	if got := innermostStack(cr, crerrbase.UnwrapOnce); !reflect.DeepEqual(got, stack) {
+		t.Errorf("expected the stack trace on the cockroachdb error")
+	}
+	if cr.Error() != err.Error() {
+		t.Errorf("expected message %q, got %q", err.Error(), cr.Error())
+	}
+
+	back := FromCockroach(cr)
+	if got := errors.GetAllHints(back); !reflect.DeepEqual(got, []string{"check the user id"}) {
+		t.Errorf("expected the hint after the round trip, got %q", got)
+	}
+	if got := innermostStack(back, errbase.UnwrapOnce); !reflect.DeepEqual(got, stack) {
+		t.Errorf("expected the stack trace after the round trip")
+	}
+	if kind := errors.GetKind(back); kind != errors.NotFoundKind {
+		t.Errorf("expected kind %v, got %v", errors.NotFoundKind, kind)
+	}
+	// The context tags only keep the rendering of the values.
+	if user, _ := errors.GetField(back, "user"); user != "42" {
+		t.Errorf("expected the field after the round trip, got %v (%T)", user, user)
+	}
+	if back.Error() != err.Error() {
+		t.Errorf("expected message %q, got %q", err.Error(), back.Error())
+	}
+
+	if ToCockroach(nil) != nil || FromCockroach(nil) != nil {
+		t.Error("expected nil")
+	}
+}
@@ -0,0 +1,28 @@
+module github.com/StevenACoffman/anotherr/errors/extcockroach
+
+go 1.19
+
+// The replace keeps the development of this module in step with the
+// root module of the repository; it is ignored by the modules that
+// depend on this one, which get the version required below. That is
+// the last published commit of the root module, and must be bumped to
+// the first one that contains the APIs used here once they are merged.
+replace github.com/StevenACoffman/anotherr => ../../
+
+require (
+	github.com/StevenACoffman/anotherr v0.0.0-20261016104232-d03b11442c6a
+	github.com/cockroachdb/errors v1.11.3
+	github.com/cockroachdb/logtags v0.0.0-20230118201751-21c54148d20b
+)
+
+require (
+	github.com/cockroachdb/redact v1.1.5 // indirect
+	github.com/getsentry/sentry-go v0.27.0 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/rogpeppe/go-internal v1.9.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
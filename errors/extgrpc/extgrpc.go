@@ -0,0 +1,93 @@
+// Package extgrpc provides error wrappers that preserve the gRPC
+// status of errors received from upstream services.
+package extgrpc
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors"
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+	"google.golang.org/grpc/status"
+)
+
+// WrapGRPCStatus wraps err with a message prefix, like errors.Wrap(),
+// and retains the gRPC status carried by err, if any. The status
+// remains available via GetGRPCStatus(), and to the gRPC library
+// itself (e.g. status.FromError() and status.Code()), however many
+// layers are added on top of it afterwards.
+//
+// If err is nil, WrapGRPCStatus returns nil.
+//
+// Detail is shown:
+// - via `errors.GetSafeDetails()`, shows the status code.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func WrapGRPCStatus(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	err = errors.WrapWithDepth(1, err, msg)
+	if !ok {
+		return err
+	}
+
+	return &withGRPCStatus{cause: err, status: st}
+}
+
+// GetGRPCStatus retrieves the gRPC status retained by
+// WrapGRPCStatus(), or otherwise carried by one of the causes of
+// err. If there are multiple, the outermost one wins.
+func GetGRPCStatus(err error) (*status.Status, bool) {
+	for c := err; c != nil; c = errors.Unwrap(c) {
+		if s, ok := c.(interface{ GRPCStatus() *status.Status }); ok {
+			if st := s.GRPCStatus(); st != nil {
+				return st, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// withGRPCStatus is an error wrapper that retains the gRPC status of
+// its cause.
+type withGRPCStatus struct {
+	cause  error
+	status *status.Status
+}
+
+var (
+	_ error         = (*withGRPCStatus)(nil)
+	_ fmt.Formatter = (*withGRPCStatus)(nil)
+)
+
+func (w *withGRPCStatus) Error() string { return w.cause.Error() }
+func (w *withGRPCStatus) Cause() error  { return w.cause }
+func (w *withGRPCStatus) Unwrap() error { return w.cause }
+
+// GRPCStatus makes the status visible to the gRPC library.
+func (w *withGRPCStatus) GRPCStatus() *status.Status { return w.status }
+
+// Format implements the fmt.Formatter interface.
+func (w *withGRPCStatus) Format(s fmt.State, verb rune) { errbase.FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *withGRPCStatus) SafeFormatError(p errbase.Printer) (next error) {
+	if p.Detail() {
+		p.Printf("grpc code: %s", w.status.Code())
+	}
+
+	return w.cause
+}
+
+// SafeDetails implements the errbase.SafeDetailer interface.
+func (w *withGRPCStatus) SafeDetails() []string {
+	return []string{w.status.Code().String()}
+}
+
+func init() {
+	errbase.RegisterRewrapper((*withGRPCStatus)(nil), func(err, newCause error) error {
+		return &withGRPCStatus{cause: newCause, status: err.(*withGRPCStatus).status}
+	})
+}
@@ -0,0 +1,35 @@
+package extgrpc
+
+import (
+	"testing"
+
+	"github.com/StevenACoffman/anotherr/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWrapGRPCStatus(t *testing.T) {
+	upstream := status.Error(codes.NotFound, "no such user")
+	err := errors.Wrap(WrapGRPCStatus(upstream, "fetching user"), "rendering profile")
+
+	st, ok := GetGRPCStatus(err)
+	if !ok {
+		t.Fatal("expected a gRPC status")
+	}
+	if st.Code() != codes.NotFound || st.Message() != "no such user" {
+		t.Errorf("expected the upstream status, got %v", st)
+	}
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("expected the gRPC library to see code %v, got %v", codes.NotFound, code)
+	}
+	if got, want := err.Error(), "rendering profile: fetching user: rpc error: code = NotFound desc = no such user"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if _, ok := GetGRPCStatus(WrapGRPCStatus(errors.New("local"), "fetching user")); ok {
+		t.Errorf("expected no status for a local error")
+	}
+	if WrapGRPCStatus(nil, "fetching user") != nil {
+		t.Errorf("expected nil for a nil error")
+	}
+}
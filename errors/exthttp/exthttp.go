@@ -0,0 +1,40 @@
+// Package exthttp provides helpers to use errors in HTTP servers.
+package exthttp
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/StevenACoffman/anotherr/errors"
+)
+
+// RecoverHTTP wraps next with a handler that recovers from the panics
+// in next. A panic is converted to an error with errors.FromPanic(),
+// logged in full with the standard logger, and the client receives
+// an error response built with errors.HTTPErrorBody(), i.e. a 500
+// with a JSON body that reveals nothing about the panic.
+//
+// The response can only be sent if next did not already start
+// writing its own.
+func RecoverHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// This panic is how handlers abort a response on
+				// purpose; the http server handles it.
+				panic(rec)
+			}
+			err := errors.FromPanic(rec)
+			log.Printf("panic serving %s %s: %+v", r.Method, r.URL.Path, err)
+			body, status := errors.HTTPErrorBody(err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write(body)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
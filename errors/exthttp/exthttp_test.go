@@ -0,0 +1,39 @@
+package exthttp
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecoverHTTP(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	h := RecoverHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("secret-token expired")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/users/42", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected a JSON response, got %q", ct)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"code":"INTERNAL"`) || strings.Contains(body, "secret-token") {
+		t.Errorf("expected an internal error revealing nothing, got %s", body)
+	}
+	out := logged.String()
+	for _, s := range []string{"panic serving GET /users/42", "secret-token expired", "stack trace:", "exthttp_test.go"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in the log:\n%s", s, out)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Package extlogrus integrates errors with the logrus logger.
+package extlogrus
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusFields converts the fields of err, merged across all its
+// layers (see errors.FieldsToKVs), to logrus fields. The kind of err
+// is added under the "kind" key, and the location where it was
+// created, if known, under the "source" key, as "file:line".
+// Returns nil if err is nil.
+func LogrusFields(err error) logrus.Fields {
+	if err == nil {
+		return nil
+	}
+	fields := logrus.Fields{}
+	kvs := errors.FieldsToKVs(err)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		fields[kvs[i].(string)] = kvs[i+1]
+	}
+	fields["kind"] = errors.GetKind(err).String()
+	if file, line, _, ok := errors.GetOneLineSource(err); ok {
+		fields["source"] = fmt.Sprintf("%s:%d", file, line)
+	}
+
+	return fields
+}
+
+// Hook is a logrus hook that adds the fields of the error logged
+// under the logrus.ErrorKey key, e.g. with logger.WithError(err), to
+// the entry. The fields already present in the entry are kept.
+//
+// Usage:
+//
+//	logger.AddHook(extlogrus.Hook{})
+type Hook struct{}
+
+var _ logrus.Hook = Hook{}
+
+// Levels implements the logrus.Hook interface.
+func (Hook) Levels() []logrus.Level { return logrus.AllLevels }
+
+// Fire implements the logrus.Hook interface.
+func (Hook) Fire(entry *logrus.Entry) error {
+	err, ok := entry.Data[logrus.ErrorKey].(error)
+	if !ok {
+		return nil
+	}
+	for k, v := range LogrusFields(err) {
+		if _, exists := entry.Data[k]; !exists {
+			entry.Data[k] = v
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package extlogrus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/anotherr/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestLogrusFields(t *testing.T) {
+	err := errors.Wrap(errors.NotFound("no user", errors.Fields{"user": 42}), "loading")
+
+	fields := LogrusFields(err)
+	if fields["kind"] != "not found" {
+		t.Errorf("expected the kind, got %v", fields["kind"])
+	}
+	if source, _ := fields["source"].(string); !strings.Contains(source, "extlogrus_test.go:") {
+		t.Errorf("expected the source of the error, got %v", fields["source"])
+	}
+	if fields["user"] != 42 || fields["message"] != "no user" {
+		t.Errorf("expected the fields of the error, got %v", fields)
+	}
+	if LogrusFields(nil) != nil {
+		t.Errorf("expected nil for a nil error")
+	}
+}
+
+func TestHook(t *testing.T) {
+	logger, entries := test.NewNullLogger()
+	logger.AddHook(Hook{})
+
+	err := errors.NotFound("no user", errors.Fields{"user": 42})
+	logger.WithError(err).WithField("user", "kept").Error("lookup failed")
+
+	entry := entries.LastEntry()
+	if entry == nil {
+		t.Fatal("expected a log entry")
+	}
+	if entry.Data["kind"] != "not found" {
+		t.Errorf("expected the kind in the entry, got %v", entry.Data)
+	}
+	if entry.Data["user"] != "kept" {
+		t.Errorf("expected the fields of the entry to be kept, got %v", entry.Data["user"])
+	}
+	if entry.Data[logrus.ErrorKey] != err {
+		t.Errorf("expected the error in the entry, got %v", entry.Data[logrus.ErrorKey])
+	}
+}
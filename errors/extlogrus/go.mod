@@ -0,0 +1,24 @@
+module github.com/StevenACoffman/anotherr/errors/extlogrus
+
+go 1.17
+
+// The replace keeps the development of this module in step with the
+// root module of the repository; it is ignored by the modules that
+// depend on this one, which get the version required below. That is
+// the last published commit of the root module, and must be bumped to
+// the first one that contains the APIs used here once they are merged.
+replace github.com/StevenACoffman/anotherr => ../../
+
+require (
+	github.com/StevenACoffman/anotherr v0.0.0-20261016104232-d03b11442c6a
+	github.com/sirupsen/logrus v1.9.3
+)
+
+require (
+	github.com/getsentry/sentry-go v0.12.0 // indirect
+	github.com/kr/pretty v0.3.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/rogpeppe/go-internal v1.6.1 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+)
@@ -0,0 +1,34 @@
+package errors
+
+import "strings"
+
+// fieldPathField is the field holding the path set by WithFieldPath.
+const fieldPathField = "field_path"
+
+// WithFieldPath records where, in a nested document, the problem
+// reported by err is located, as a JSON pointer (RFC 6901) such as
+// "/items/3/name". Paths compose as err is wrapped on the way up: the
+// validation of an item can set "name", and the validation of the
+// list prepend "/items/3" to it. A leading "/" is added to path when
+// missing; the segments themselves are expected to be escaped
+// already. If err is nil, WithFieldPath returns nil.
+func WithFieldPath(err error, path string) error {
+	if err == nil {
+		return nil
+	}
+	path = strings.TrimSuffix(path, "/")
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return WrapWithFieldsAndDepth(err, Fields{fieldPathField: path + GetFieldPath(err)}, 1)
+}
+
+// GetFieldPath returns the full path recorded on err, and its causes,
+// with WithFieldPath(), or "" if there is none.
+func GetFieldPath(err error) string {
+	path, _ := GetField(err, fieldPathField)
+	s, _ := path.(string)
+
+	return s
+}
@@ -0,0 +1,22 @@
+package errors
+
+import "testing"
+
+func TestWithFieldPath(t *testing.T) {
+	err := WithFieldPath(InvalidInput("required"), "name")
+	if got := GetFieldPath(err); got != "/name" {
+		t.Errorf("expected %q, got %q", "/name", got)
+	}
+
+	err = WithFieldPath(Wrap(err, "validating item"), "/items/3")
+	if got := GetFieldPath(err); got != "/items/3/name" {
+		t.Errorf("expected %q, got %q", "/items/3/name", got)
+	}
+
+	if got := GetFieldPath(New("boom")); got != "" {
+		t.Errorf("expected no path, got %q", got)
+	}
+	if WithFieldPath(nil, "name") != nil {
+		t.Error("expected nil")
+	}
+}
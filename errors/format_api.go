@@ -0,0 +1,58 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// SetSortEntriesByStackDepth controls the order of the "Wraps: (N)"
+// entries printed with %+v. By default, they follow the chain of
+// causes from the outermost error to the innermost. When enabled, the
+// entries are ordered by the depth of their stack trace instead, the
+// deepest first.
+// See errbase.SetSortEntriesByStackDepth() for details.
+func SetSortEntriesByStackDepth(enabled bool) { errbase.SetSortEntriesByStackDepth(enabled) }
+
+// SetWrapsHeader changes the label printed with %+v in front of the
+// number of each cause of an error, "Wraps:" by default.
+// See errbase.SetWrapsHeader() for details.
+func SetWrapsHeader(header string) { errbase.SetWrapsHeader(header) }
+
+// SetMaxFormattedLayers limits the number of layers of an error that
+// are detailed when formatting it with %+v. A value of 0 or less, the
+// default, means no limit.
+// See errbase.SetMaxFormattedLayers() for details.
+func SetMaxFormattedLayers(n int) { errbase.SetMaxFormattedLayers(n) }
+
+// StackPathMode is how the file paths of stack frames are printed.
+type StackPathMode = errbase.StackPathMode
+
+const (
+	// StackPathAbsolute prints the absolute file paths. This is the
+	// default.
+	StackPathAbsolute = errbase.StackPathAbsolute
+	// StackPathRelative prints the file paths relative to the root of
+	// their module.
+	StackPathRelative = errbase.StackPathRelative
+	// StackPathBaseOnly only prints the file names.
+	StackPathBaseOnly = errbase.StackPathBaseOnly
+)
+
+// SetStackPathMode changes how the file paths of the stack frames are
+// printed with %+v.
+// See errbase.SetStackPathMode() for details.
+func SetStackPathMode(mode StackPathMode) { errbase.SetStackPathMode(mode) }
+
+// SetShowErrorTypesTrailer controls whether %+v ends with the
+// "Error types:" line. Shown by default.
+// See errbase.SetShowErrorTypesTrailer() for details.
+func SetShowErrorTypesTrailer(show bool) { errbase.SetShowErrorTypesTrailer(show) }
+
+// SetFormatCache controls whether the output of formatting an error is
+// memoized, for errors printed repeatedly with the same verb.
+// Disabled by default.
+// See errbase.SetFormatCache() for details.
+func SetFormatCache(enabled bool) { errbase.SetFormatCache(enabled) }
+
+// ListRegisteredFormatters returns the names of the special-case
+// printers that take over the formatting of some errors, in the order
+// in which they are tried.
+// See errbase.ListRegisteredFormatters() for details.
+func ListRegisteredFormatters() []string { return errbase.ListRegisteredFormatters() }
@@ -0,0 +1,208 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFormatInvalidUTF8(t *testing.T) {
+	const msg = "ctx: bad \xff\xfe byte"
+	err := Wrap(New("bad \xff\xfe byte"), "ctx")
+
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{"%v", msg},
+		{"%s", msg},
+		{"%q", strconv.Quote(msg)},
+		{"%x", fmt.Sprintf("%x", msg)},
+	} {
+		// Format twice: the output must not depend on earlier calls.
+		for i := 0; i < 2; i++ {
+			if got := fmt.Sprintf(tc.format, err); got != tc.want {
+				t.Errorf("%s: expected %q, got %q", tc.format, tc.want, got)
+			}
+		}
+	}
+}
+
+func TestFormatCacheFollowsSettings(t *testing.T) {
+	SetFormatCache(true)
+	defer SetFormatCache(false)
+
+	err := WrapWithFields(New("x"), Fields{"a": 1, "b": 2, "token": "format-cache-secret"})
+	before := fmt.Sprintf("%+v", err)
+	if !strings.Contains(before, "format-cache-secret") {
+		t.Fatalf("expected the token in %%+v, got:\n%s", before)
+	}
+
+	var saved []*regexp.Regexp
+	redactionPatterns.Read(func() { saved = redactionPatterns.patterns })
+	defer redactionPatterns.Update(func() { redactionPatterns.patterns = saved })
+	RegisterRedactionPattern(regexp.MustCompile(`^format-cache-secret$`))
+	if got := fmt.Sprintf("%+v", err); strings.Contains(got, "format-cache-secret") {
+		t.Errorf("the cache served a rendering made before the redaction pattern:\n%s", got)
+	}
+
+	SetMaxFormattedFields(1)
+	defer SetMaxFormattedFields(0)
+	if got := fmt.Sprintf("%+v", err); !strings.Contains(got, "(2 more fields)") {
+		t.Errorf("the cache served a rendering made before SetMaxFormattedFields:\n%s", got)
+	}
+}
+
+func BenchmarkFormatRepeated(b *testing.B) {
+	err := Wrap(WrapWithFields(NotFound("no user"), Fields{"user": 42}), "loading")
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cache=%t", cached), func(b *testing.B) {
+			SetFormatCache(cached)
+			defer SetFormatCache(false)
+			for i := 0; i < b.N; i++ {
+				_ = fmt.Sprintf("%+v", err)
+			}
+		})
+	}
+}
+
+func TestFormatDepth(t *testing.T) {
+	err := WithHint(WithMessage(stderrors.New("root"), "loading"), "retry")
+
+	if got := fmt.Sprintf("%d", err); got != "3" {
+		t.Errorf("expected %%d to print 3, got %q", got)
+	}
+	if got := fmt.Sprintf("%+d", err); got != "3" {
+		t.Errorf("expected %%+d to print 3, got %q", got)
+	}
+	if got := fmt.Sprintf("%v", err); got != "loading: root" {
+		t.Errorf("expected %%v to be unchanged, got %q", got)
+	}
+}
+
+func TestSortEntriesByStackDepth(t *testing.T) {
+	var inner error
+	atDepth(5, func() { inner = New("inner") })
+	err := Wrap(inner, "outer")
+
+	// firstEntry returns the rendering of the entry (1) in s.
+	firstEntry := func(s string) string { return s[:strings.Index(s, "\nWraps: (2)")] }
+
+	verbose := fmt.Sprintf("%+v", err)
+	if strings.Contains(firstEntry(verbose), "atDepth") {
+		t.Errorf("expected the outermost stack first by default, got:\n%s", verbose)
+	}
+
+	SetSortEntriesByStackDepth(true)
+	defer SetSortEntriesByStackDepth(false)
+	sorted := fmt.Sprintf("%+v", err)
+	if !strings.Contains(firstEntry(sorted), "atDepth") {
+		t.Errorf("expected the deepest stack first, got:\n%s", sorted)
+	}
+	const types = "Error types: (1) *withstack.withStack (2) *withstack.withStack (3) *errutil.withPrefix (4) *errutil.leafError"
+	if !strings.HasSuffix(sorted, types) {
+		t.Errorf("expected the entries without stack last, got:\n%s", sorted)
+	}
+}
+
+func TestSetWrapsHeader(t *testing.T) {
+	err := Wrap(New("root"), "loading")
+
+	SetWrapsHeader("Caused by:")
+	defer SetWrapsHeader("")
+	verbose := fmt.Sprintf("%+v", err)
+	if !strings.Contains(verbose, "\nCaused by: (2) loading\n") {
+		t.Errorf("expected the custom header, got:\n%s", verbose)
+	}
+	if strings.Contains(verbose, "Wraps:") {
+		t.Errorf("expected no default header, got:\n%s", verbose)
+	}
+}
+
+func TestMaxFormattedLayers(t *testing.T) {
+	err := stderrors.New("root")
+	for i := 1; i < 50; i++ {
+		err = WithMessage(err, strconv.Itoa(i))
+	}
+
+	SetMaxFormattedLayers(10)
+	defer SetMaxFormattedLayers(0)
+	verbose := fmt.Sprintf("%+v", err)
+	if !strings.Contains(verbose, "... (40 more layers elided)") {
+		t.Errorf("expected the elision marker, got:\n%s", verbose)
+	}
+	if !strings.Contains(verbose, "\nWraps: (10) ") || strings.Contains(verbose, "Wraps: (11)") {
+		t.Errorf("expected 10 entries, got:\n%s", verbose)
+	}
+	if first := verbose[:strings.Index(verbose, "\n")]; first != err.Error() {
+		t.Errorf("expected the complete message on the first line, got %q", first)
+	}
+}
+
+func TestFormatQuotedDetail(t *testing.T) {
+	err := Wrap(NotFound("no user", Fields{"user": 42}), "loading \"profile\"")
+
+	got := fmt.Sprintf("%+q", err)
+	if want := strconv.Quote(fmt.Sprintf("%+v", err)); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if got := fmt.Sprintf("%q", err); got != strconv.Quote(err.Error()) {
+		t.Errorf("expected %%q to quote the message only, got %s", got)
+	}
+}
+
+func TestFormatSpaceFlagInlinesFields(t *testing.T) {
+	err := Wrap(WrapWithFields(New("boom"), Fields{"user": 42, "id": "a"}), "loading")
+
+	if got := fmt.Sprintf("%v", err); got != "loading: boom" {
+		t.Errorf("expected %%v without fields, got %q", got)
+	}
+	if got := fmt.Sprintf("% v", err); got != "loading: [id:a, user:42]: boom" {
+		t.Errorf("expected %% v with the fields inline, got %q", got)
+	}
+	if got := err.Error(); got != "loading: boom" {
+		t.Errorf("expected Error() without fields, got %q", got)
+	}
+}
+
+func TestSetStackPathMode(t *testing.T) {
+	err, l := New("boom"), line()
+	defer SetStackPathMode(StackPathAbsolute)
+
+	for _, tc := range []struct {
+		mode StackPathMode
+		want *regexp.Regexp
+	}{
+		{StackPathAbsolute, regexp.MustCompile(`\n  \| \t/\S+/errors/format_api_test\.go:` + strconv.Itoa(l) + `\n`)},
+		{StackPathRelative, regexp.MustCompile(`\n  \| \terrors/format_api_test\.go:` + strconv.Itoa(l) + `\n`)},
+		{StackPathBaseOnly, regexp.MustCompile(`\n  \| \tformat_api_test\.go:` + strconv.Itoa(l) + `\n`)},
+	} {
+		SetStackPathMode(tc.mode)
+		if verbose := fmt.Sprintf("%+v", err); !tc.want.MatchString(verbose) {
+			t.Errorf("mode %d: expected a frame matching %s, got:\n%s", tc.mode, tc.want, verbose)
+		}
+	}
+}
+
+func TestSetShowErrorTypesTrailer(t *testing.T) {
+	err := Wrap(stderrors.New("root"), "loading")
+
+	if verbose := fmt.Sprintf("%+v", err); !strings.Contains(verbose, "\nError types: (1) ") {
+		t.Errorf("expected the trailer by default, got:\n%s", verbose)
+	}
+
+	SetShowErrorTypesTrailer(false)
+	defer SetShowErrorTypesTrailer(true)
+	verbose := fmt.Sprintf("%+v", err)
+	if strings.Contains(verbose, "Error types:") {
+		t.Errorf("expected no trailer, got:\n%s", verbose)
+	}
+	for _, want := range []string{"\n(1) attached stack trace\n", "\nWraps: (2) loading\n", "\nWraps: (3) root"} {
+		if !strings.Contains(verbose, want) {
+			t.Errorf("expected the numbered entry %q, got:\n%s", want, verbose)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/hintdetail"
+
+// ErrorHinter is implemented by types that can provide
+// user-informing detail strings.
+type ErrorHinter = hintdetail.ErrorHinter
+
+// WithHint decorates an error with a textual hint.
+// The hint may contain PII and thus will not be reportable.
+//
+// Hint is shown:
+// - when formatting with `%+v`.
+// - with `GetAllHints()` / `FlattenHints()` below.
+//
+// Note: the hint does not appear in the main error message returned
+// with Error(). Use GetAllHints() or FlattenHints() to retrieve it.
+func WithHint(err error, msg string) error { return hintdetail.WithHint(err, msg) }
+
+// WithHintf is a helper that formats the hint.
+func WithHintf(err error, format string, args ...interface{}) error {
+	return hintdetail.WithHintf(err, format, args...)
+}
+
+// GetAllHints retrieves the hints from the error using in post-order
+// traversal, i.e. innermost first. The hints are de-duplicated.
+func GetAllHints(err error) []string { return hintdetail.GetAllHints(err) }
+
+// FlattenHints retrieves the hints as per GetAllHints() and
+// concatenates them into a single string.
+func FlattenHints(err error) string { return hintdetail.FlattenHints(err) }
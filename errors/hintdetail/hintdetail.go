@@ -0,0 +1,124 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package hintdetail
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// WithHint decorates an error with a textual hint.
+// The hint may contain PII and thus will not be reportable.
+//
+// Hint is shown:
+// - when formatting with `%+v`.
+// - with `GetAllHints()` / `FlattenHints()` below.
+//
+// Note: the hint does not appear in the main error message returned
+// with Error(). Use GetAllHints() or FlattenHints() to retrieve it.
+func WithHint(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withHint{cause: err, hint: msg}
+}
+
+// WithHintf is a helper that formats the hint.
+func WithHintf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withHint{cause: err, hint: fmt.Sprintf(format, args...)}
+}
+
+// ErrorHinter is implemented by types that can provide
+// user-informing detail strings. This is implemented by withHint
+// here.
+type ErrorHinter interface {
+	ErrorHint() string
+}
+
+// GetAllHints retrieves the hints from the error using in post-order
+// traversal, i.e. innermost first. The hints are de-duplicated.
+func GetAllHints(err error) []string {
+	return getAllHintsInternal(err, nil, make(map[string]struct{}))
+}
+
+// FlattenHints retrieves the hints as per GetAllHints() and
+// concatenates them into a single string.
+func FlattenHints(err error) string {
+	var b strings.Builder
+	sep := ""
+	for _, h := range GetAllHints(err) {
+		b.WriteString(sep)
+		b.WriteString(h)
+		sep = "\n--\n"
+	}
+
+	return b.String()
+}
+
+func getAllHintsInternal(err error, hints []string, seen map[string]struct{}) []string {
+	if c := errbase.UnwrapOnce(err); c != nil {
+		hints = getAllHintsInternal(c, hints, seen)
+	}
+
+	if w, ok := err.(ErrorHinter); ok {
+		hint := w.ErrorHint()
+		if _, ok := seen[hint]; !ok {
+			hints = append(hints, hint)
+			seen[hint] = struct{}{}
+		}
+	}
+
+	return hints
+}
+
+type withHint struct {
+	cause error
+	hint  string
+}
+
+var (
+	_ ErrorHinter   = (*withHint)(nil)
+	_ fmt.Formatter = (*withHint)(nil)
+)
+
+func (w *withHint) ErrorHint() string { return w.hint }
+func (w *withHint) Error() string     { return w.cause.Error() }
+func (w *withHint) Cause() error      { return w.cause }
+func (w *withHint) Unwrap() error     { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *withHint) Format(s fmt.State, verb rune) { errbase.FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *withHint) SafeFormatError(p errbase.Printer) error {
+	if p.Detail() {
+		p.Printf("hint: %s", w.hint)
+	}
+
+	return w.cause
+}
+
+func init() {
+	errbase.RegisterRewrapper((*withHint)(nil), func(err, newCause error) error {
+		return &withHint{cause: newCause, hint: err.(*withHint).hint}
+	})
+}
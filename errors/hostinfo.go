@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"os"
+	"sync"
+)
+
+// The fields holding the host information recorded by WithHostInfo.
+const (
+	hostnameField = "hostname"
+	pidField      = "pid"
+)
+
+// osHostname is os.Hostname, as a variable for tests.
+var osHostname = os.Hostname
+
+// hostInfo caches the host information, which is resolved once, on
+// the first call to WithHostInfo.
+var hostInfo struct {
+	once     sync.Once
+	hostname string
+	pid      int
+}
+
+// WithHostInfo records the name of the host and the PID of the
+// process as the fields "hostname" and "pid", in a new layer around
+// err, so that the errors aggregated across a fleet identify the
+// instance that produced them even when the log pipeline strips that
+// metadata. The host information is resolved once and cached. If the
+// hostname cannot be resolved, only the PID is recorded. If err is
+// nil, WithHostInfo returns nil.
+func WithHostInfo(err error) error {
+	if err == nil {
+		return nil
+	}
+	hostInfo.once.Do(func() {
+		hostInfo.hostname, _ = osHostname()
+		hostInfo.pid = os.Getpid()
+	})
+	fields := Fields{pidField: hostInfo.pid}
+	if hostInfo.hostname != "" {
+		fields[hostnameField] = hostInfo.hostname
+	}
+
+	return WrapWithFieldsAndDepth(err, fields, 1)
+}
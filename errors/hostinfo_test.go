@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestWithHostInfo(t *testing.T) {
+	defer func(saved func() (string, error)) {
+		osHostname = saved
+		hostInfo.once = sync.Once{}
+	}(osHostname)
+	hostInfo.once = sync.Once{}
+	calls := 0
+	osHostname = func() (string, error) {
+		calls++
+
+		return "web-42", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		err := WithHostInfo(New("boom"))
+		if host, _ := GetField(err, hostnameField); host != "web-42" {
+			t.Errorf("expected hostname %q, got %v", "web-42", host)
+		}
+		if pid, _ := GetField(err, pidField); pid != os.Getpid() {
+			t.Errorf("expected pid %d, got %v", os.Getpid(), pid)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the hostname resolved once, got %d calls", calls)
+	}
+	if WithHostInfo(nil) != nil {
+		t.Error("expected nil")
+	}
+}
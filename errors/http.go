@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// httpKinds describes how errors of each kind are exposed over HTTP:
+// their status code, and the code reported in the body of the
+// response when the error has no code of its own (see WithCode).
+// Errors of other kinds, including UnspecifiedKind, are exposed like
+// InternalKind.
+var httpKinds = map[errorKind]struct {
+	status int
+	code   string
+}{
+	NotFoundKind:             {http.StatusNotFound, "NOT_FOUND"},
+	InvalidInputKind:         {http.StatusBadRequest, "INVALID_INPUT"},
+	NotAllowedKind:           {http.StatusConflict, "NOT_ALLOWED"},
+	UnauthorizedKind:         {http.StatusForbidden, "UNAUTHORIZED"},
+	InternalKind:             {http.StatusInternalServerError, "INTERNAL"},
+	NotImplementedKind:       {http.StatusNotImplemented, "NOT_IMPLEMENTED"},
+	GraphqlResponseKind:      {http.StatusBadGateway, "GRAPHQL_RESPONSE"},
+	TransientKhanServiceKind: {http.StatusServiceUnavailable, "TRANSIENT_KHAN_SERVICE"},
+	KhanServiceKind:          {http.StatusBadGateway, "KHAN_SERVICE"},
+	TransientServiceKind:     {http.StatusServiceUnavailable, "TRANSIENT_SERVICE"},
+	ServiceKind:              {http.StatusBadGateway, "SERVICE"},
+}
+
+// HTTPStatusCode returns the HTTP status code of the response to send
+// when a request fails with err, based on its kind (see EffectiveKind).
+// For example, NotFoundKind maps to 404 and InvalidInputKind to 400.
+// Errors without a kind map to 500. Returns 200 if err is nil.
+func HTTPStatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if k, ok := httpKinds[EffectiveKind(err)]; ok {
+		return k.status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// httpErrorBody is the JSON body of an error response.
+type httpErrorBody struct {
+	Error struct {
+		Code    string                 `json:"code"`
+		Message string                 `json:"message"`
+		Details map[string]interface{} `json:"details"`
+	} `json:"error"`
+}
+
+// HTTPErrorBody returns the JSON body and the status code (see
+// HTTPStatusCode) of the response to send when a request fails with
+// err. The body has the shape:
+//
+//	{"error": {"code": "NOT_FOUND", "message": "...", "details": {...}}}
+//
+// The code is the one attached with WithCode(), if any, otherwise a
+// code derived from the kind of err. The message is the one meant for
+// end users (see UserMessage). The details only include what was
+// meant to be shown to the client, i.e. the per-field messages of
+// validation errors (see NewValidationError), under
+// "validation_errors". Neither the message of err nor its fields are
+// included, since they may contain internal details or PII.
+func HTTPErrorBody(err error) ([]byte, int) {
+	var body httpErrorBody
+	code, ok := GetCode(err)
+	if !ok {
+		code = httpKinds[InternalKind].code
+		if k, ok := httpKinds[EffectiveKind(err)]; ok {
+			code = k.code
+		}
+	}
+	body.Error.Code = code
+	body.Error.Message = UserMessage(err)
+	body.Error.Details = map[string]interface{}{}
+	if v := GetValidationErrors(err); v != nil {
+		body.Error.Details[validationErrorsField] = v
+	}
+	// The body only contains strings, so it cannot fail to encode.
+	data, _ := json.Marshal(body)
+
+	return data, HTTPStatusCode(err)
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHTTPErrorBody(t *testing.T) {
+	err := Wrap(NotFound("no user bob@example.com", Fields{"email": "bob@example.com"}), "loading")
+
+	body, status := HTTPErrorBody(err)
+	if status != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, status)
+	}
+	const want = `{"error":{"code":"NOT_FOUND","message":"The requested resource was not found.","details":{}}}`
+	if string(body) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, body)
+	}
+	if strings.Contains(string(body), "bob@example.com") {
+		t.Errorf("the body leaks PII: %s", body)
+	}
+}
+
+func TestHTTPErrorBodyValidation(t *testing.T) {
+	err := WithCode(NewValidationError(map[string]string{"name": "is required"}), "BAD_SIGNUP")
+
+	body, status := HTTPErrorBody(err)
+	if status != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, status)
+	}
+	const want = `{"error":{"code":"BAD_SIGNUP","message":"The request was invalid.","details":{"validation_errors":{"name":"is required"}}}}`
+	if string(body) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, body)
+	}
+}
+
+func TestHTTPStatusCode(t *testing.T) {
+	got := []int{
+		HTTPStatusCode(nil),
+		HTTPStatusCode(InvalidInput("bad")),
+		HTTPStatusCode(New("plain")),
+		HTTPStatusCode(TransientService(New("timeout"))),
+	}
+	want := []int{http.StatusOK, http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestHTTPErrorBodyEffectiveKind(t *testing.T) {
+	_, openErr := os.Open(filepath.Join(t.TempDir(), "missing.txt"))
+	err := Wrap(openErr, "open")
+
+	body, status := HTTPErrorBody(err)
+	if status != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, status)
+	}
+	const want = `{"error":{"code":"NOT_FOUND","message":"The requested resource was not found.","details":{}}}`
+	if string(body) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, body)
+	}
+
+	var saved map[errorKind]bool
+	reportableKinds.Read(func() { saved = reportableKinds.kinds })
+	defer reportableKinds.Update(func() { reportableKinds.kinds = saved })
+	SetReportableKinds(InternalKind, UnspecifiedKind)
+	if ShouldReport(err) {
+		t.Errorf("expected ShouldReport to use the inferred kind")
+	}
+}
@@ -0,0 +1,195 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// jsonError is the JSON representation of one layer in a chain of
+// errors. Stack traces are not included: they only make sense in the
+// process where they were captured.
+type jsonError struct {
+	// Type is the Go type of the layer, as printed by %T.
+	Type string `json:"type"`
+	// Message is the full message for a leaf error, and the message
+	// prefix (if any) for a wrapper.
+	Message string `json:"message,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+	Fields  Fields `json:"fields,omitempty"`
+	// Code is the code attached with WithCode, if any.
+	Code string `json:"code,omitempty"`
+	// Cause is the next layer in the chain, if any.
+	Cause *jsonError `json:"cause,omitempty"`
+}
+
+// ToJSON encodes err, and all its causes, to JSON.
+//
+// Field values are included verbatim when they can be represented in
+// JSON. Other values (e.g. funcs or channels) are replaced by the
+// result of formatting them with %v.
+func ToJSON(err error) ([]byte, error) {
+	return json.Marshal(toJSONError(err))
+}
+
+// EncodeJSON writes the encoding of err, and all its causes, to w. The
+// output is the same as that of ToJSON(), but it is written layer by
+// layer, instead of being built in memory at once, for very large
+// errors sent e.g. to a log socket.
+func EncodeJSON(w io.Writer, err error) error {
+	depth := 0
+	for c := err; c != nil; {
+		if cause, ok := unencodedCause(c); ok {
+			c = cause
+
+			continue
+		}
+		cause := errbase.UnwrapOnce(c)
+		data, err := json.Marshal(toJSONLayer(c, cause))
+		if err != nil {
+			return err
+		}
+		if depth > 0 {
+			if _, err := io.WriteString(w, `,"cause":`); err != nil {
+				return err
+			}
+		}
+		// The closing brace is written after the causes, below.
+		if _, err := w.Write(data[:len(data)-1]); err != nil {
+			return err
+		}
+		depth++
+		c = cause
+	}
+	if depth == 0 {
+		_, err := io.WriteString(w, "null")
+
+		return err
+	}
+	_, err = io.WriteString(w, strings.Repeat("}", depth))
+
+	return err
+}
+
+// FromJSON decodes an error previously encoded with ToJSON. The
+// second return value reports a problem with the decoding itself.
+// The result is tagged as remote (see IsRemote).
+//
+// Khan errors, fields and codes are decoded to their original types. Other
+// errors are decoded to opaque errors that preserve their message,
+// but not their type; errbase.TypeName() reports their original type.
+// As with any JSON, numeric field values are decoded as float64.
+func FromJSON(data []byte) (error, error) {
+	var je *jsonError
+	if err := json.Unmarshal(data, &je); err != nil {
+		return nil, err
+	}
+
+	return markRemote(fromJSONError(je)), nil
+}
+
+func toJSONError(err error) *jsonError {
+	if err == nil {
+		return nil
+	}
+	if cause, ok := unencodedCause(err); ok {
+		return toJSONError(cause)
+	}
+	cause := errbase.UnwrapOnce(err)
+	je := toJSONLayer(err, cause)
+	je.Cause = toJSONError(cause)
+
+	return je
+}
+
+// unencodedCause returns the cause of err if err is a wrapper that
+// only records something about the current process, e.g. that the
+// error was logged, and is thus not encoded.
+func unencodedCause(err error) (error, bool) {
+	switch w := err.(type) {
+	case *remoteError:
+		return w.cause, true
+	case *loggedError:
+		return w.cause, true
+	}
+
+	return nil, false
+}
+
+// toJSONLayer returns the JSON representation of err alone, without
+// its cause.
+func toJSONLayer(err, cause error) *jsonError {
+	je := &jsonError{Type: errbase.TypeName(err)}
+
+	switch e := err.(type) {
+	case *khanError:
+		je.Kind = string(e.kind)
+		je.Fields = jsonSafeFields(e.fields)
+	case *withFields:
+		je.Fields = jsonSafeFields(e.fields)
+	case *withCode:
+		je.Code = e.code
+	case errorKind:
+		je.Kind = string(e)
+		je.Message = e.Error()
+	default:
+		if cause == nil {
+			je.Message = err.Error()
+		} else {
+			je.Message = errbase.ExtractPrefix(err, cause)
+		}
+	}
+
+	return je
+}
+
+// jsonSafeFields returns a copy of fields where the values that cannot
+// be encoded to JSON are replaced by their %v rendering.
+func jsonSafeFields(fields Fields) Fields {
+	if len(fields) == 0 {
+		return nil
+	}
+	res := make(Fields, len(fields))
+	for k, v := range fields {
+		if _, err := json.Marshal(v); err != nil {
+			v = fmt.Sprintf("%v", v)
+		}
+		res[k] = v
+	}
+
+	return res
+}
+
+// The type names of the errors that FromJSON decodes to their
+// original type.
+var (
+	errorKindTypeName  = fmt.Sprintf("%T", UnspecifiedKind)
+	khanErrorTypeName  = fmt.Sprintf("%T", (*khanError)(nil))
+	withFieldsTypeName = fmt.Sprintf("%T", (*withFields)(nil))
+	withCodeTypeName   = fmt.Sprintf("%T", (*withCode)(nil))
+)
+
+func fromJSONError(je *jsonError) error {
+	if je == nil {
+		return nil
+	}
+	cause := fromJSONError(je.Cause)
+
+	switch {
+	case je.Type == errorKindTypeName && cause == nil:
+		return errorKind(je.Kind)
+	case je.Type == khanErrorTypeName && cause != nil:
+		return &khanError{kind: errorKind(je.Kind), cause: cause, fields: je.Fields, stack: &stack{}}
+	case je.Type == withFieldsTypeName && cause != nil:
+		return &withFields{cause: cause, fields: je.Fields, stack: &stack{}}
+	case je.Type == withCodeTypeName && cause != nil:
+		return &withCode{cause: cause, code: je.Code}
+	case cause == nil:
+		return errbase.NewOpaqueLeaf(je.Message, je.Type)
+	}
+
+	return errbase.NewOpaqueWrapper(cause, je.Message, je.Type)
+}
@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	err := Wrap(WrapWithFields(NotFound("no user"), Fields{
+		"name":    "bob",
+		"ratio":   1.5,
+		"count":   42,
+		"admin":   true,
+		"tags":    []string{"a", "b"},
+		"complex": complex(1, 2),
+	}), "loading")
+
+	data, jsonErr := ToJSON(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	decoded, jsonErr := FromJSON(data)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	if got, want := decoded.Error(), err.Error(); got != want {
+		t.Errorf("expected message %q, got %q", want, got)
+	}
+	if kind := GetKind(decoded); kind != NotFoundKind {
+		t.Errorf("expected kind %v, got %v", NotFoundKind, kind)
+	}
+	want := Fields{
+		"message": "no user",
+		"name":    "bob",
+		"ratio":   1.5,
+		// JSON numbers are decoded as float64.
+		"count": float64(42),
+		"admin": true,
+		"tags":  []interface{}{"a", "b"},
+		// Values that cannot be encoded to JSON are stringified.
+		"complex": "(1+2i)",
+	}
+	if got := GetFields(decoded); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected fields %v, got %v", want, got)
+	}
+}
+
+func TestEncodeJSONMatchesToJSON(t *testing.T) {
+	data, jsonErr := ToJSON(Wrap(NotFound("no user"), "loading"))
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	decoded, jsonErr := FromJSON(data)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	for _, err := range []error{
+		New("boom"),
+		Wrap(WrapWithFields(NotFound("no user"), Fields{"user": 42, "complex": complex(1, 2)}), "loading"),
+		WithHint(WithSafeFields(Internal(New("bug")), Fields{"status": 500}), "retry"),
+		Wrap(decoded, "handling"),
+		nil,
+	} {
+		want, jsonErr := ToJSON(err)
+		if jsonErr != nil {
+			t.Fatal(jsonErr)
+		}
+		var buf bytes.Buffer
+		if jsonErr := EncodeJSON(&buf, err); jsonErr != nil {
+			t.Fatal(jsonErr)
+		}
+		if got := buf.Bytes(); !bytes.Equal(got, want) {
+			t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+		}
+	}
+}
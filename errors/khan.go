@@ -5,11 +5,24 @@ package errors
 
 import (
 	"fmt"
+
 	"github.com/StevenACoffman/anotherr/errors/errbase"
+	"github.com/StevenACoffman/anotherr/errors/errutil"
 )
 
 type errorKind string
 
+// ErrorKind is the type of the kinds of errors, e.g. NotFoundKind, as
+// returned by GetKind(). It lets callers name the type, e.g. in the
+// signature of a function mapping kinds to HTTP status codes:
+//
+//	func httpStatus(kind errors.ErrorKind) int
+//
+// Its String() method returns the name of the kind, for logging. It is
+// an alias, so that the Go type of the kinds, as reported by %T and
+// preserved by ToJSON(), is unchanged.
+type ErrorKind = errorKind
+
 // Error is a function that makes errorKind implement the error interface. This
 // lets us use error.Is with kinds. We don't actually use the output of this
 // function for anything.
@@ -28,13 +41,13 @@ const (
 	// resource couldn't be retrieved due to access control use
 	// UnauthorizedKind instead. If the resource couldn't be found because
 	// the input was invalid use InvalidInputKind instead.
-	NotFoundKind errorKind = "not found"
+	NotFoundKind ErrorKind = "not found"
 
 	// InvalidInputKind means that there was a problem with the provided input.
 	// This kind indicates inputs that are problematic regardless of the state
 	// of the system. Use NotAllowedKind when the input is valid but
 	// conflicts with the state of the system.
-	InvalidInputKind errorKind = "invalid input error"
+	InvalidInputKind ErrorKind = "invalid input error"
 
 	// NotAllowedKind means that there was a problem due to the state of
 	// the system not matching the requested operation or input. For
@@ -42,18 +55,18 @@ const (
 	// taken by another user. Use InvalidInputKind when the input isn't
 	// valid regardless of the state of the system. Use NotFoundKind when
 	// the failure is due to not being able to find a resource.
-	NotAllowedKind errorKind = "not allowed"
+	NotAllowedKind ErrorKind = "not allowed"
 
 	// UnauthorizedKind means that there was an access control problem.
-	UnauthorizedKind errorKind = "unauthorized error"
+	UnauthorizedKind ErrorKind = "unauthorized error"
 
 	// InternalKind means that the function failed for a reason unrelated
 	// to its input or problems working with a remote system. Use this kind
 	// when other error kinds aren't appropriate.
-	InternalKind errorKind = "internal error"
+	InternalKind ErrorKind = "internal error"
 
 	// NotImplementedKind means that the function isn't implemented.
-	NotImplementedKind errorKind = "not implemented error"
+	NotImplementedKind ErrorKind = "not implemented error"
 
 	// GraphqlResponseKind means that the graphql server returned an
 	// error code as part of the graphql response.  This kind of error
@@ -62,28 +75,28 @@ const (
 	// successfully executes, but the graphql response struct
 	// indicates the graphql request could not be executed due to an
 	// error.  (e.g. mutation.MyMutation.Error.Code == "UNAUTHORIZED")
-	GraphqlResponseKind errorKind = "graphql error response"
+	GraphqlResponseKind ErrorKind = "graphql error response"
 
 	// TransientKhanServiceKind means that there was a problem when contacting
 	// another Khan service that might be resolvable by retrying.
-	TransientKhanServiceKind errorKind = "transient khan service error"
+	TransientKhanServiceKind ErrorKind = "transient khan service error"
 
 	// KhanServiceKind means that there was a non-transient problem when
 	// contacting another Khan service.
-	KhanServiceKind errorKind = "khan service error"
+	KhanServiceKind ErrorKind = "khan service error"
 
 	// TransientServiceKind means that there was a problem when making a
 	// request to a non-Khan service, e.g. datastore that might be
 	// resolvable by retrying.
-	TransientServiceKind errorKind = "transient service error"
+	TransientServiceKind ErrorKind = "transient service error"
 
 	// ServiceKind means that there was a non-transient problem when making a
 	// request to a non-Khan service, e.g. datastore.
-	ServiceKind errorKind = "service error"
+	ServiceKind ErrorKind = "service error"
 
 	// UnspecifiedKind means that no error kind was specified. Note that there
 	// isn't a constructor for this kind of error.
-	UnspecifiedKind errorKind = "unspecified error"
+	UnspecifiedKind ErrorKind = "unspecified error"
 )
 
 // KhanWrap takes a khanError as input and some new field key/value pairs,
@@ -91,29 +104,49 @@ const (
 // error, plus the specified key/value pairs.  For convenience, rather
 // than using errors.Fields{} to specify the key/value pairs, they
 // are specified as alternating string/interface{} objects.
-// Also for convenience, if nil is passed in then nil is returned.
+// Also for convenience, if nil is passed in then nil is returned, as
+// is the case for a nil pointer in a non-nil error (see IsTypedNil).
+//
+// For consistency with the kind constructors (see NotFound), args can
+// also include:
+// - errors.Fields{} objects, in place of a key: their key/value pairs
+// are merged in. A later Fields object wins over an earlier one, and
+// the alternating key/value pairs win over all Fields objects.
+// - an error, as the first or the last arg: it is attached to the
+// result as a secondary error (see WithSecondaryError), so that it is
+// shown by %+v but affects neither the message nor Is().
 //
 // If there is an error in wrapping -- the input is not a khanError,
 // a non-string key is specified -- then the wrapped error is actually
 // an error.Internal() that indicates the problem with wrapping.
 func KhanWrap(err error, args ...interface{}) error {
-	if err == nil {
+	if err == nil || errbase.IsTypedNil(err) {
 		return nil
 	}
 
-	if len(args)%2 != 0 {
-		return newError(
-			InternalKind,
-			err,
-			Fields{
-				"fields":  args,
-				"message": "Passed an odd number of field-args to errors.Wrap()",
-			},
-		)
-	}
+	fields, pairs := Fields{}, Fields{}
+	var otherErr error
+	for i := 0; i < len(args); i++ {
+		switch v := args[i].(type) {
+		case Fields:
+			for k, val := range v {
+				fields[k] = val
+			}
 
-	fields := Fields{}
-	for i := 0; i < len(args); i += 2 {
+			continue
+		case map[string]interface{}:
+			for k, val := range v {
+				fields[k] = val
+			}
+
+			continue
+		case error:
+			if i == 0 || i == len(args)-1 {
+				otherErr = v
+
+				continue
+			}
+		}
 		key, ok := args[i].(string)
 		if !ok {
 			return newError(
@@ -122,28 +155,44 @@ func KhanWrap(err error, args ...interface{}) error {
 				Fields{"key": args[i], "message": "Passed a non-string key-field to errors.Wrap()"},
 			)
 		}
-		fields[key] = args[i+1]
+		if i+1 == len(args) {
+			return newError(
+				InternalKind,
+				err,
+				Fields{
+					"fields":  args,
+					"message": "Passed an odd number of field-args to errors.Wrap()",
+				},
+			)
+		}
+		i++
+		pairs[key] = args[i]
 	}
-
-	khanErr, ok := err.(*khanError)
-	khanKind, kindOfOk := err.(errorKind)
-	if !ok { // root is not KhanErr
-		if kindOfOk { // root is errorKind
-			return newError(khanKind, err, fields)
+	for k, v := range pairs {
+		fields[k] = v
+	}
+	// "Internal" is the best default, but not always right.
+	// e.g. for client.GCS() errors, "Service" would be better.
+	// The solution is to change our GCS wrapper to return khanErrors,
+	// like we do for our Datastore wrapper.
+	kind := InternalKind
+	switch e := err.(type) {
+	case *khanError:
+		// UnspecifiedKind probably can't happen, but just in case...
+		if e.kind != UnspecifiedKind {
+			kind = e.kind
 		}
-		// "Internal" is the best default, but not always right.
-		// e.g. for client.GCS() errors, "Service" would be better.
-		// The solution is to change our GCS wrapper to return khanErrors,
-		// like we do for our Datastore wrapper.
-		return newError(InternalKind, err, fields)
+	case errorKind:
+		kind = e
 	}
-
-	errKind := khanErr.kind
-	if errKind == UnspecifiedKind {
-		// This probably can't happen, but just in case...
-		return newError(InternalKind, args...)
+	// The hook of OnCreate() is called once, with the final error,
+	// after the secondary error is attached.
+	res := makeErrorWithDepth(1, kind, err, fields)
+	if otherErr != nil {
+		res = WithSecondaryError(res, otherErr)
 	}
-	return newError(errKind, khanErr, fields)
+
+	return errutil.NotifyCreated(res)
 }
 
 // NotFound creates an error of kind NotFoundKind.  args can be
@@ -152,58 +201,169 @@ func KhanWrap(err error, args ...interface{}) error {
 // (3) an errors.Fields{} object of key/value pairs to associate with the error
 // (4) an errors.Source("source-location") to override the default source-loc
 // If you specify any of these multiple times, only the last one wins.
+//
+// The same goes for all the other kind constructors below. Calling
+// them with no args creates a fresh error of their kind. Like Wrap(),
+// they return nil when given a nil error to wrap, so that
+//
+//	return errors.NotFound(err)
+//
+// returns nil if err is nil. This includes a nil pointer in a non-nil
+// error (see IsTypedNil).
 func NotFound(args ...interface{}) error {
-	return KhanWrap(NotFoundKind, args...)
+	return newKindError(NotFoundKind, args...)
 }
 
 // InvalidInput creates an error of kind InvalidKind.
 func InvalidInput(args ...interface{}) error {
-	return KhanWrap(InvalidInputKind, args...)
+	return newKindError(InvalidInputKind, args...)
 }
 
 // NotAllowed creates an error of kind NotAllowedKind.
 func NotAllowed(args ...interface{}) error {
-	return KhanWrap(NotAllowedKind, args...)
+	return newKindError(NotAllowedKind, args...)
 }
 
 // Unauthorized creates an error of kind UnauthorizedKind.
 func Unauthorized(args ...interface{}) error {
-	return KhanWrap(UnauthorizedKind, args...)
+	return newKindError(UnauthorizedKind, args...)
 }
 
 // Internal creates an error of kind InternalKind.
 func Internal(args ...interface{}) error {
-	return KhanWrap(InternalKind, args...)
+	return newKindError(InternalKind, args...)
 }
 
 // GraphqlResponse creates an error of kind GraphqlResponseKind.
 func GraphqlResponse(args ...interface{}) error {
-	return KhanWrap(GraphqlResponseKind, args...)
+	return newKindError(GraphqlResponseKind, args...)
 }
 
 // NotImplemented creates an error of kind NotImplementedKind.
 func NotImplemented(args ...interface{}) error {
-	return KhanWrap(NotImplementedKind, args...)
+	return newKindError(NotImplementedKind, args...)
 }
 
 // TransientKhanService creates an error of kind TransientKhanServiceKind.
 func TransientKhanService(args ...interface{}) error {
-	return KhanWrap(TransientKhanServiceKind, args...)
+	return newKindError(TransientKhanServiceKind, args...)
 }
 
 // KhanService creates an error of kind KhanServiceKind.
 func KhanService(args ...interface{}) error {
-	return KhanWrap(KhanServiceKind, args...)
+	return newKindError(KhanServiceKind, args...)
 }
 
 // Service creates an error of kind ServiceKind.
 func Service(args ...interface{}) error {
-	return KhanWrap(ServiceKind, args...)
+	return newKindError(ServiceKind, args...)
 }
 
 // TransientService creates an error of kind TransientServiceKind.
 func TransientService(args ...interface{}) error {
-	return KhanWrap(TransientServiceKind, args...)
+	return newKindError(TransientServiceKind, args...)
+}
+
+// IsTransient returns true if any error in the chain is of kind
+// TransientServiceKind or TransientKhanServiceKind. These are the
+// errors that might be resolved by retrying.
+func IsTransient(err error) bool {
+	return Is(err, TransientServiceKind) || Is(err, TransientKhanServiceKind)
+}
+
+// IsServiceError returns true if any error in the chain is one of the
+// service kinds: ServiceKind, TransientServiceKind, KhanServiceKind or
+// TransientKhanServiceKind. That is, the failure happened while
+// talking to some other service, Khan or not.
+func IsServiceError(err error) bool {
+	return Is(err, ServiceKind) ||
+		Is(err, TransientServiceKind) ||
+		Is(err, KhanServiceKind) ||
+		Is(err, TransientKhanServiceKind)
+}
+
+// GetKind returns the kind of err: the kind of the first (outermost)
+// Khan error in the chain, or the errorKind itself if the chain ends
+// in one. Returns UnspecifiedKind if there is no kind in the chain.
+//
+// The chain is walked from err itself down to its root cause, so with
+// nested calls such as KhanWrap(NotFound(...), ...), or an Internal()
+// wrapping a NotFound(), the outermost kind wins: it reflects the
+// latest classification of the error. See KindOf() for the innermost
+// kind. The branches of multi-errors are walked in order (see
+// errbase.Walk). Handlers can branch on the result:
+//
+//	switch errors.GetKind(err) {
+//	case errors.NotFoundKind:
+//		w.WriteHeader(http.StatusNotFound)
+//	...
+//	}
+func GetKind(err error) ErrorKind {
+	kind := UnspecifiedKind
+	errbase.Walk(err, func(c error) bool {
+		switch e := c.(type) {
+		case *khanError:
+			kind = e.kind
+
+			return false
+		case errorKind:
+			kind = e
+
+			return false
+		}
+
+		return true
+	})
+
+	return kind
+}
+
+// KindOf returns the kind of the innermost Khan error (or errorKind)
+// in the chain of err, and whether one was found at all. Unlike
+// GetKind, this distinguishes unclassified errors from errors that
+// were explicitly given UnspecifiedKind.
+func KindOf(err error) (ErrorKind, bool) {
+	kind, found := UnspecifiedKind, false
+	errbase.Walk(err, func(c error) bool {
+		switch e := c.(type) {
+		case *khanError:
+			kind, found = e.kind, true
+		case errorKind:
+			kind, found = e, true
+		}
+
+		return true
+	})
+
+	return kind, found
+}
+
+// UnwrapToSubstantive skips over the layers of err that only annotate
+// their cause (e.g. stack traces, message prefixes, fields) and returns
+// the first error in the chain that either carries a kind or is a leaf.
+// The layers are visited as with errbase.Walk(), so the branches of a
+// multi-error are searched in order. Returns nil if err is nil.
+func UnwrapToSubstantive(err error) error {
+	var res error
+	errbase.Walk(err, func(c error) bool {
+		switch c.(type) {
+		case *khanError, errorKind:
+			res = c
+
+			return false
+		case interface{ Unwrap() []error }:
+			return true
+		}
+		if errbase.UnwrapOnce(c) == nil {
+			res = c
+
+			return false
+		}
+
+		return true
+	})
+
+	return res
 }
 
 type khanError struct {
@@ -213,7 +373,33 @@ type khanError struct {
 	kind errorKind
 }
 
+// newKindError implements the kind constructors, e.g. NotFound(). A
+// nil arg can only be a nil error to wrap, in which case the result is
+// nil too.
+func newKindError(kind errorKind, args ...interface{}) error {
+	for _, arg := range args {
+		if err, ok := arg.(error); arg == nil || ok && errbase.IsTypedNil(err) {
+			return nil
+		}
+	}
+
+	return newErrorWithDepth(2, kind, args...)
+}
+
 func newError(kind errorKind, args ...interface{}) error {
+	return newErrorWithDepth(2, kind, args...)
+}
+
+// newErrorWithDepth creates a Khan error of the given kind out of
+// args, as described in NotFound(), capturing the stack trace depth
+// frames above the caller, and passes it to the hook of OnCreate().
+func newErrorWithDepth(depth int, kind errorKind, args ...interface{}) error {
+	return errutil.NotifyCreated(makeErrorWithDepth(depth+1, kind, args...))
+}
+
+// makeErrorWithDepth is like newErrorWithDepth, without calling the
+// hook of OnCreate(), for the callers that wrap the result further.
+func makeErrorWithDepth(depth int, kind errorKind, args ...interface{}) error {
 	var message string
 	var cause error
 	var fields Fields
@@ -239,13 +425,13 @@ func newError(kind errorKind, args ...interface{}) error {
 		}
 	}
 
-	return khanWrapWithFieldsAndDepth(kind, cause, fields, 2)
+	return khanWrapWithFieldsAndDepth(kind, cause, fields, depth+1)
 }
 
 // WrapWithFieldsAndDepth adds fields to an existing error
 // and captures the stacktrace
 func khanWrapWithFieldsAndDepth(kind errorKind, err error, fields Fields, depth int) error {
-	if err == nil {
+	if err == nil || errbase.IsTypedNil(err) {
 		return nil
 	}
 
@@ -253,12 +439,50 @@ func khanWrapWithFieldsAndDepth(kind errorKind, err error, fields Fields, depth
 }
 
 // it's an error.
-func (ke *khanError) Error() string { return ke.cause.Error() }
+func (ke *khanError) Error() string {
+	if !shouldIncludeKindInError() || ke.cause == ke.kind {
+		return ke.cause.Error()
+	}
+
+	return fmt.Sprintf("[%s] %s", ke.kind, ke.cause.Error())
+}
+
+// includeKindInError, when set, makes Error() of Khan errors start
+// with their kind.
+var includeKindInError = struct {
+	errbase.Registry
+	enabled bool
+}{}
+
+// SetIncludeKindInError controls whether the Error() method of Khan
+// errors includes their kind, e.g. "[not found] resource X missing",
+// for the benefit of logs that only capture Error(). Each Khan error
+// in the chain includes its own kind where it sits in the message.
+// When the error was created without a message, e.g. NotFound(), its
+// message is the kind already and is unchanged. Formatting with %v
+// and %+v is not affected, nor are Is() and As(). Disabled by default.
+func SetIncludeKindInError(enabled bool) {
+	includeKindInError.Update(func() { includeKindInError.enabled = enabled })
+}
+
+func shouldIncludeKindInError() (enabled bool) {
+	includeKindInError.Read(func() { enabled = includeKindInError.enabled })
+
+	return enabled
+}
 
 // Cause makes it also a wrapper.
 func (ke *khanError) Cause() error  { return ke.cause }
 func (ke *khanError) Unwrap() error { return ke.cause }
 
+// Is makes errors.Is(err, SomeKind) match on the kind of the error,
+// even when the cause is some other error.
+func (ke *khanError) Is(reference error) bool {
+	kind, ok := reference.(errorKind)
+
+	return ok && kind == ke.kind
+}
+
 // Format knows how to format itself.
 func (ke *khanError) Format(s fmt.State, verb rune) { errbase.FormatError(ke, s, verb) }
 
@@ -267,17 +491,14 @@ func (ke *khanError) Format(s fmt.State, verb rune) { errbase.FormatError(ke, s,
 // on how to implement this. In particular beware of not emitting
 // unsafe strings.
 func (ke *khanError) SafeFormatError(p errbase.Printer) (next error) {
+	if errbase.WantsInlineDetail(p) {
+		printInlineFields(p, visibleFields(ke))
+	}
 	if p.Detail() {
 		p.Printf("kind: %s", ke.kind)
-		if ke.fields != nil {
-			p.Printf("fields: [")
-			fieldsIterate(ke.fields, func(i int, r string) {
-				if i > 0 {
-					p.Printf(", ")
-				}
-				p.Print(r)
-			})
-			p.Printf("]")
+		if fields := visibleFields(ke); len(fields) != 0 {
+			p.Printf("\n")
+			printFields(p, fields)
 		}
 	}
 	// We do not print the stack trace ourselves - errbase.FormatError()
@@ -290,6 +511,14 @@ func (ke *khanError) SafeDetails() []string {
 	return []string{fmt.Sprintf("%+v", ke.StackTrace())}
 }
 
+func init() {
+	errbase.RegisterRewrapper((*khanError)(nil), func(err, newCause error) error {
+		ke := err.(*khanError)
+
+		return &khanError{kind: ke.kind, cause: newCause, fields: ke.fields, stack: ke.stack}
+	})
+}
+
 //
 //func (ke *khanError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 //	enc.AddString("kind", string(ke.kind))
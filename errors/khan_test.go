@@ -0,0 +1,197 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnwrapToSubstantiveThroughMultiError(t *testing.T) {
+	nf := NotFound("no user")
+	err := Wrap(multiError{WithHint(nf, "h"), New("b")}, "loading")
+
+	if got := UnwrapToSubstantive(err); got != nf {
+		t.Errorf("expected the not found error, got %v", got)
+	}
+}
+
+func TestKindOnlyError(t *testing.T) {
+	err := NotFound()
+
+	if got := err.Error(); got != "not found" {
+		t.Errorf("expected Error() to be the kind, got %q", got)
+	}
+	verbose := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(verbose, "not found\n(1) kind: not found\n  -- stack trace:\n") {
+		t.Errorf("expected the kind then the stack trace in %%+v, got:\n%s", verbose)
+	}
+	if !strings.Contains(verbose, "\nWraps: (2) not found\n") {
+		t.Errorf("expected the kind as the leaf in %%+v, got:\n%s", verbose)
+	}
+	if strings.Contains(verbose, "fields:") {
+		t.Errorf("unexpected fields in %%+v:\n%s", verbose)
+	}
+	if kind := GetKind(err); kind != NotFoundKind {
+		t.Errorf("expected kind %v, got %v", NotFoundKind, kind)
+	}
+}
+
+func TestGetKindOutermostWins(t *testing.T) {
+	err := Internal(Wrap(NotFound("no user"), "loading"))
+
+	var kind ErrorKind = GetKind(err)
+	if kind != InternalKind {
+		t.Errorf("expected the outermost kind %v, got %v", InternalKind, kind)
+	}
+	if kind, ok := KindOf(Wrap(New("x"), "y")); ok || kind != UnspecifiedKind {
+		t.Errorf("expected no kind, got %v, %t", kind, ok)
+	}
+	if kind := GetKind(InvalidInputKind); kind != InvalidInputKind {
+		t.Errorf("expected a bare kind to be its own kind, got %v", kind)
+	}
+}
+
+func TestKindFamilies(t *testing.T) {
+	for _, tc := range []struct {
+		kind      ErrorKind
+		transient bool
+		service   bool
+	}{
+		{NotFoundKind, false, false},
+		{InvalidInputKind, false, false},
+		{NotAllowedKind, false, false},
+		{UnauthorizedKind, false, false},
+		{InternalKind, false, false},
+		{NotImplementedKind, false, false},
+		{GraphqlResponseKind, false, false},
+		{TransientKhanServiceKind, true, true},
+		{KhanServiceKind, false, true},
+		{TransientServiceKind, true, true},
+		{ServiceKind, false, true},
+	} {
+		err := Wrap(newKindError(tc.kind, "failed"), "loading")
+		if got := IsTransient(err); got != tc.transient {
+			t.Errorf("IsTransient(%s): expected %v, got %v", tc.kind, tc.transient, got)
+		}
+		if got := IsServiceError(err); got != tc.service {
+			t.Errorf("IsServiceError(%s): expected %v, got %v", tc.kind, tc.service, got)
+		}
+	}
+}
+
+func TestUnwrapToSubstantive(t *testing.T) {
+	ke := Internal(New("boom"), Fields{"user": 42})
+	err := WithHint(WrapWithFields(Wrap(WithStack(ke), "saving"), Fields{"a": 1}), "retry later")
+
+	if got := UnwrapToSubstantive(err); got != ke {
+		t.Errorf("expected the khan error, got %v", got)
+	}
+	root := stderrors.New("plain")
+	if got := UnwrapToSubstantive(Wrap(root, "saving")); got != root {
+		t.Errorf("expected the leaf, got %v", got)
+	}
+	if got := UnwrapToSubstantive(nil); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	err := Internal(Wrap(NotFound("no user"), "loading"))
+	if kind, ok := KindOf(err); !ok || kind != NotFoundKind {
+		t.Errorf("expected the innermost kind %v, got %v, %v", NotFoundKind, kind, ok)
+	}
+	if !Is(err, InternalKind) || !Is(err, NotFoundKind) {
+		t.Errorf("expected Is() to match both kinds in the chain")
+	}
+
+	if kind, ok := KindOf(Wrap(New("plain"), "loading")); ok || kind != UnspecifiedKind {
+		t.Errorf("expected no kind, got %v, %v", kind, ok)
+	}
+}
+
+func TestKindConstructorsNilHandling(t *testing.T) {
+	var nilErr error
+	var typedNil *rootError
+
+	for _, tc := range []struct {
+		name    string
+		err     error
+		wantNil bool
+	}{
+		{"nil error", NotFound(nilErr), true},
+		{"nil error with message", NotFound(nilErr, "no user"), true},
+		{"typed nil error", NotFound(typedNil), true},
+		{"message", NotFound("no user"), false},
+		{"message and fields", NotFound("no user", Fields{"user": 42}), false},
+		{"no args", NotFound(), false},
+		{"KhanWrap nil", KhanWrap(nil, "loading"), true},
+		{"WrapWithFields nil", WrapWithFields(nil, Fields{"user": 42}), true},
+	} {
+		if got := tc.err == nil; got != tc.wantNil {
+			t.Errorf("%s: expected nil=%v, got %v", tc.name, tc.wantNil, tc.err)
+		}
+		if tc.err != nil && GetKind(tc.err) != NotFoundKind {
+			t.Errorf("%s: expected kind %v, got %v", tc.name, NotFoundKind, GetKind(tc.err))
+		}
+	}
+}
+
+func TestSetIncludeKindInError(t *testing.T) {
+	err := Wrap(NotFound(New("resource X missing")), "loading")
+	kindOnly := NotFound()
+
+	if got := err.Error(); got != "loading: resource X missing" {
+		t.Errorf("expected no kind by default, got %q", got)
+	}
+
+	SetIncludeKindInError(true)
+	defer SetIncludeKindInError(false)
+	if got := err.Error(); got != "loading: [not found] resource X missing" {
+		t.Errorf("expected the kind in the message, got %q", got)
+	}
+	if got := kindOnly.Error(); got != "not found" {
+		t.Errorf("expected the message of a kind-only error to be unchanged, got %q", got)
+	}
+	if got := fmt.Sprintf("%v", err); got != "loading: resource X missing" {
+		t.Errorf("expected %%v to be unaffected, got %q", got)
+	}
+}
+
+func TestKhanWrapMixedArgs(t *testing.T) {
+	base := NotFound("no user")
+	other := New("cache miss")
+	err := KhanWrap(base, other, Fields{"user": 42, "source": "fields"}, "source", "pair", Fields{"user": 43}, "attempt", 2)
+
+	if kind := GetKind(err); kind != NotFoundKind {
+		t.Errorf("expected the kind of the wrapped error, got %v", kind)
+	}
+	fields := GetFields(err)
+	// A later Fields object wins over an earlier one, and the pairs win
+	// over all Fields objects.
+	if fields["user"] != 43 || fields["source"] != "pair" || fields["attempt"] != 2 {
+		t.Errorf("unexpected fields %v", fields)
+	}
+	if verbose := fmt.Sprintf("%+v", err); !strings.Contains(verbose, "cache miss") {
+		t.Errorf("expected the leading error as secondary error, got:\n%s", verbose)
+	}
+	if Is(err, other) {
+		t.Errorf("the secondary error must not participate in Is()")
+	}
+
+	err = KhanWrap(base, "attempt", 2, other)
+	if verbose := fmt.Sprintf("%+v", err); !strings.Contains(verbose, "cache miss") {
+		t.Errorf("expected the trailing error as secondary error, got:\n%s", verbose)
+	}
+}
+
+func TestKhanWrapBadArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		{42, "value"},
+		{"key", "value", "odd"},
+	} {
+		if kind := GetKind(KhanWrap(NotFound("no user"), args...)); kind != InternalKind {
+			t.Errorf("KhanWrap(%v): expected kind %v, got %v", args, InternalKind, kind)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// The <Kind>WithFields() functions in this file are the explicit
+// counterparts of the kind constructors, e.g. NotFound(), for the
+// common case of wrapping an error with a message and fields at once.
+// <Kind>WithFields(err, msg, fields) is equivalent to
+// <Kind>(err, msg, fields), without the ambiguity of the variadic
+// form: it creates a single Khan error of the given kind, with a
+// single stack trace. The message, if not empty, is stored as the
+// "message" field. If err is nil, they return nil.
+
+// newKindErrorWithFields implements the constructors below. The
+// message, if not empty, is stored as the "message" field, like with
+// the kind constructors. fields is not modified.
+func newKindErrorWithFields(kind errorKind, err error, msg string, fields Fields) error {
+	if err == nil || errbase.IsTypedNil(err) {
+		return nil
+	}
+	all := make(Fields, len(fields)+1)
+	for k, v := range fields {
+		all[k] = v
+	}
+
+	return newErrorWithDepth(2, kind, err, msg, all)
+}
+
+// NotFoundWithFields wraps err with the kind NotFoundKind, a message and fields.
+func NotFoundWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(NotFoundKind, err, msg, fields)
+}
+
+// InvalidInputWithFields wraps err with the kind InvalidInputKind, a message and fields.
+func InvalidInputWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(InvalidInputKind, err, msg, fields)
+}
+
+// NotAllowedWithFields wraps err with the kind NotAllowedKind, a message and fields.
+func NotAllowedWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(NotAllowedKind, err, msg, fields)
+}
+
+// UnauthorizedWithFields wraps err with the kind UnauthorizedKind, a message and fields.
+func UnauthorizedWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(UnauthorizedKind, err, msg, fields)
+}
+
+// InternalWithFields wraps err with the kind InternalKind, a message and fields.
+func InternalWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(InternalKind, err, msg, fields)
+}
+
+// GraphqlResponseWithFields wraps err with the kind GraphqlResponseKind, a message and fields.
+func GraphqlResponseWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(GraphqlResponseKind, err, msg, fields)
+}
+
+// NotImplementedWithFields wraps err with the kind NotImplementedKind, a message and fields.
+func NotImplementedWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(NotImplementedKind, err, msg, fields)
+}
+
+// TransientKhanServiceWithFields wraps err with the kind TransientKhanServiceKind, a message and fields.
+func TransientKhanServiceWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(TransientKhanServiceKind, err, msg, fields)
+}
+
+// KhanServiceWithFields wraps err with the kind KhanServiceKind, a message and fields.
+func KhanServiceWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(KhanServiceKind, err, msg, fields)
+}
+
+// ServiceWithFields wraps err with the kind ServiceKind, a message and fields.
+func ServiceWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(ServiceKind, err, msg, fields)
+}
+
+// TransientServiceWithFields wraps err with the kind TransientServiceKind, a message and fields.
+func TransientServiceWithFields(err error, msg string, fields Fields) error {
+	return newKindErrorWithFields(TransientServiceKind, err, msg, fields)
+}
@@ -0,0 +1,30 @@
+package errors
+
+import "testing"
+
+func TestInternalWithFields(t *testing.T) {
+	root := New("connection reset")
+	fields := Fields{"user": 42}
+	err := InternalWithFields(root, "saving", fields)
+
+	if kind := GetKind(err); kind != InternalKind {
+		t.Errorf("expected kind %v, got %v", InternalKind, kind)
+	}
+	got := GetFields(err)
+	if got["message"] != "saving" || got["user"] != 42 {
+		t.Errorf("expected the message and the fields, got %v", got)
+	}
+	if len(fields) != 1 {
+		t.Errorf("expected the fields argument to be left alone, got %v", fields)
+	}
+	// One stack for New(), one for InternalWithFields().
+	if n := StackCount(err); n != 2 {
+		t.Errorf("expected a single stack on top of the root's, got %d", n)
+	}
+	if !Is(err, root) {
+		t.Errorf("expected the cause to be preserved")
+	}
+	if InternalWithFields(nil, "saving", fields) != nil {
+		t.Errorf("expected nil for a nil cause")
+	}
+}
@@ -0,0 +1,59 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// kindMappings is the list of the sentinel errors registered with
+// RegisterKindMapping(), in registration order.
+var kindMappings = struct {
+	errbase.Registry
+	mappings []kindMapping
+}{}
+
+type kindMapping struct {
+	sentinel error
+	kind     errorKind
+}
+
+// RegisterKindMapping declares that the errors that match sentinel
+// (see Is) have the given kind when they were not classified
+// otherwise, e.g. sql.ErrNoRows has NotFoundKind:
+//
+//	errors.RegisterKindMapping(sql.ErrNoRows, errors.NotFoundKind)
+//
+// This is used by EffectiveKind(). When an error matches several
+// sentinels, the mapping registered first wins.
+//
+// This should be called at init time, but is safe to call
+// concurrently with EffectiveKind().
+func RegisterKindMapping(sentinel error, kind ErrorKind) {
+	kindMappings.Update(func() {
+		kindMappings.mappings = append(kindMappings.mappings, kindMapping{sentinel, kind})
+	})
+}
+
+// EffectiveKind returns the kind of err, like GetKind(), except that
+// if err has no kind, the sentinel errors registered with
+// RegisterKindMapping() are matched against its chain to infer one.
+// Failing that, the errors of the os package are recognized:
+// os.ErrNotExist maps to NotFoundKind and os.ErrPermission to
+// UnauthorizedKind, including when wrapped in an *os.PathError or
+// *os.SyscallError. Returns UnspecifiedKind if none matches.
+func EffectiveKind(err error) ErrorKind {
+	if kind := GetKind(err); kind != UnspecifiedKind || err == nil {
+		return kind
+	}
+	var mappings []kindMapping
+	kindMappings.Read(func() { mappings = kindMappings.mappings })
+	for _, m := range mappings {
+		if Is(err, m.sentinel) {
+			return m.kind
+		}
+	}
+	for _, m := range osKindMappings {
+		if Is(err, m.sentinel) {
+			return m.kind
+		}
+	}
+
+	return UnspecifiedKind
+}
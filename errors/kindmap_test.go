@@ -0,0 +1,28 @@
+package errors
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestEffectiveKindFromMapping(t *testing.T) {
+	var saved []kindMapping
+	kindMappings.Read(func() { saved = kindMappings.mappings })
+	defer kindMappings.Update(func() { kindMappings.mappings = saved })
+
+	err := Wrap(sql.ErrNoRows, "loading user")
+	if kind := EffectiveKind(err); kind != UnspecifiedKind {
+		t.Errorf("expected no kind before the mapping, got %v", kind)
+	}
+
+	RegisterKindMapping(sql.ErrNoRows, NotFoundKind)
+	if kind := EffectiveKind(err); kind != NotFoundKind {
+		t.Errorf("expected kind %v, got %v", NotFoundKind, kind)
+	}
+	if kind := GetKind(err); kind != UnspecifiedKind {
+		t.Errorf("expected GetKind to ignore the mapping, got %v", kind)
+	}
+	if kind := EffectiveKind(Internal(err)); kind != InternalKind {
+		t.Errorf("expected an explicit kind to win over the mapping, got %v", kind)
+	}
+}
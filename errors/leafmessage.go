@@ -0,0 +1,42 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// ReplaceLeafMessage returns a copy of err where the message of the
+// root cause is replaced by newMsg, e.g. to remove PII from it before
+// logging. Everything else in the chain is preserved: the message
+// prefixes, fields, kinds and stack traces of the wrappers.
+//
+// A Khan error created without an error to wrap, e.g. NotFound("user
+// bob@example.com not found"), keeps its message in its "message"
+// field, its cause being its kind: that field is the one replaced, so
+// that the message does not show up in GetFields() or %+v anymore, and
+// the kind is kept.
+//
+// Otherwise, the new root cause is an opaque error that retains the
+// name of the type of the original one (see errbase.TypeName), but not
+// its identity: Is() no longer matches the original root cause, e.g. a
+// sentinel error or a kind. GetKind() is unaffected when the kind is
+// carried by a Khan error in the chain. Returns nil if err is nil.
+func ReplaceLeafMessage(err error, newMsg string) error {
+	if err == nil {
+		return nil
+	}
+	if ke, ok := err.(*khanError); ok && ke.cause == error(ke.kind) {
+		if _, ok := ke.fields["message"]; ok {
+			fields := make(Fields, len(ke.fields))
+			for k, v := range ke.fields {
+				fields[k] = v
+			}
+			fields["message"] = newMsg
+
+			return &khanError{kind: ke.kind, cause: ke.cause, fields: fields, stack: ke.stack}
+		}
+	}
+	cause := errbase.UnwrapOnce(err)
+	if cause == nil {
+		return errbase.NewOpaqueLeaf(newMsg, errbase.TypeName(err))
+	}
+
+	return errbase.Rewrap(err, ReplaceLeafMessage(cause, newMsg))
+}
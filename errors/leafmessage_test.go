@@ -0,0 +1,66 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+func TestReplaceLeafMessage(t *testing.T) {
+	leaf := stderrors.New("no user bob@example.com")
+	err := Wrap(WrapWithFields(Wrap(leaf, "loading user"), Fields{"user": 42}), "handling request")
+
+	got := ReplaceLeafMessage(err, "no user <redacted>")
+	if want := "handling request: loading user: no user <redacted>"; got.Error() != want {
+		t.Errorf("expected %q, got %q", want, got.Error())
+	}
+	if want := (Fields{"user": 42}); !reflect.DeepEqual(GetFields(got), want) {
+		t.Errorf("expected fields %v, got %v", want, GetFields(got))
+	}
+	if n, want := StackCount(got), StackCount(err); n != want {
+		t.Errorf("expected %d stack traces, got %d", want, n)
+	}
+	if name, want := errbase.TypeName(Cause(got)), errbase.TypeName(leaf); name != want {
+		t.Errorf("expected the root cause type %q, got %q", want, name)
+	}
+	if err.Error() != "handling request: loading user: no user bob@example.com" {
+		t.Errorf("expected the original error unchanged, got %q", err.Error())
+	}
+
+	kind := ReplaceLeafMessage(Internal(leaf), "redacted")
+	if GetKind(kind) != InternalKind {
+		t.Errorf("expected the kind preserved, got %v", GetKind(kind))
+	}
+	if ReplaceLeafMessage(nil, "x") != nil {
+		t.Error("expected nil")
+	}
+}
+
+func TestReplaceLeafMessageOfKhanError(t *testing.T) {
+	err := Wrap(NotFound("user bob@example.com not found", Fields{"user": 42}), "loading")
+
+	got := ReplaceLeafMessage(err, "user <redacted> not found")
+	if want := (Fields{"user": 42, "message": "user <redacted> not found"}); !reflect.DeepEqual(GetFields(got), want) {
+		t.Errorf("expected fields %v, got %v", want, GetFields(got))
+	}
+	verbose := fmt.Sprintf("%+v", got)
+	if strings.Contains(verbose, "bob@example.com") {
+		t.Errorf("expected the message replaced in %%+v, got:\n%s", verbose)
+	}
+	if !strings.Contains(verbose, "user <redacted> not found") {
+		t.Errorf("expected the new message in %%+v, got:\n%s", verbose)
+	}
+	if GetKind(got) != NotFoundKind || !Is(got, NotFoundKind) {
+		t.Errorf("expected the kind preserved, got %v", GetKind(got))
+	}
+	if got.Error() != "loading: not found" {
+		t.Errorf("expected the message of the error unchanged, got %q", got.Error())
+	}
+	if msg, _ := GetField(err, "message"); msg != "user bob@example.com not found" {
+		t.Errorf("expected the original error unchanged, got %v", msg)
+	}
+}
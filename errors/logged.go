@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// MarkLogged records that err has been logged, in a new layer around
+// err, so that the code further up the stack can tell, with
+// WasLogged(), and avoid logging it again:
+//
+//	log.Printf("%+v", err)
+//	return errors.MarkLogged(err)
+//
+// The marker survives wrapping, but not encoding (see ToJSON): the
+// receiver of an error has not logged it yet. If err is nil,
+// MarkLogged returns nil.
+func MarkLogged(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &loggedError{cause: err}
+}
+
+// WasLogged returns true if err, or any of its causes, was marked
+// with MarkLogged().
+func WasLogged(err error) bool {
+	return !errbase.Walk(err, func(c error) bool {
+		_, ok := c.(*loggedError)
+
+		return !ok
+	})
+}
+
+// loggedError is the wrapper added by MarkLogged(). Like remoteError,
+// it is not encoded.
+type loggedError struct {
+	cause error
+}
+
+var (
+	_ error         = (*loggedError)(nil)
+	_ fmt.Formatter = (*loggedError)(nil)
+)
+
+func (w *loggedError) Error() string { return w.cause.Error() }
+func (w *loggedError) Cause() error  { return w.cause }
+func (w *loggedError) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *loggedError) Format(s fmt.State, verb rune) { errbase.FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *loggedError) SafeFormatError(p errbase.Printer) (next error) {
+	if p.Detail() {
+		p.Print("already logged")
+	}
+
+	return w.cause
+}
+
+func init() {
+	errbase.RegisterRewrapper((*loggedError)(nil), func(_, newCause error) error {
+		return &loggedError{cause: newCause}
+	})
+}
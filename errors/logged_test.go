@@ -0,0 +1,34 @@
+package errors
+
+import "testing"
+
+func TestWasLogged(t *testing.T) {
+	root := NotFound("no user")
+	if WasLogged(root) {
+		t.Error("expected an unmarked error not to be logged")
+	}
+
+	err := Wrap(WithHint(MarkLogged(root), "check the id"), "handling request")
+	if !WasLogged(err) {
+		t.Error("expected the marker to survive wrapping")
+	}
+	if err.Error() != "handling request: not found" || !Is(err, root) {
+		t.Errorf("expected the marker to be transparent, got %q", err.Error())
+	}
+
+	data, jsonErr := ToJSON(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	decoded, jsonErr := FromJSON(data)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if WasLogged(decoded) {
+		t.Error("expected the marker not to be encoded")
+	}
+
+	if MarkLogged(nil) != nil {
+		t.Error("expected nil")
+	}
+}
@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// FormatMarkdown renders err, and all its causes, as Markdown, for
+// pasting into issue trackers such as GitHub or Jira. The message
+// comes first, then a section per layer, from the outermost to the
+// innermost, with the kind in bold, the fields as a bulleted list and
+// the stack trace in a fenced code block:
+//
+//	**Error:** not found
+//
+//	#### (1) `*errors.khanError`
+//
+//	**Kind:** not found
+//
+//	- `user`: 42
+//
+//	```
+//	main.loadUser
+//		/src/app/user.go:12
+//	```
+//
+// The layers are visited as with errbase.Walk(), like for Dump(): the
+// section of a multi-error gives its number of branches, and is
+// followed by the sections of the layers of each branch in turn.
+// Returns "" if err is nil.
+func FormatMarkdown(err error) string {
+	if err == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Error:** %s\n", err.Error())
+	n := 0
+	errbase.Walk(err, func(c error) bool {
+		n++
+		fmt.Fprintf(&b, "\n#### (%d) `%s`\n", n, errbase.TypeName(c))
+		msg := c.Error()
+		if cause := errbase.UnwrapOnce(c); cause != nil {
+			msg = errbase.ExtractPrefix(c, cause)
+		} else if m, ok := c.(interface{ Unwrap() []error }); ok {
+			msg = fmt.Sprintf("**Branches:** %d", len(m.Unwrap()))
+		}
+		if msg != "" {
+			fmt.Fprintf(&b, "\n%s\n", msg)
+		}
+		if ke, ok := c.(*khanError); ok {
+			fmt.Fprintf(&b, "\n**Kind:** %s\n", ke.kind)
+		}
+		if fields := visibleFields(c); len(fields) != 0 {
+			keys := make([]string, 0, len(fields))
+			for k := range fields {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			b.WriteByte('\n')
+			for _, k := range keys {
+				fmt.Fprintf(&b, "- `%s`: %v\n", k, redactFieldValue(fields[k]))
+			}
+		}
+		if p, ok := c.(errbase.StackTraceProvider); ok {
+			if st := p.StackTrace(); len(st) != 0 {
+				fmt.Fprintf(&b, "\n```\n%s\n```\n", strings.TrimPrefix(fmt.Sprintf("%+v", st), "\n"))
+			}
+		}
+
+		return true
+	})
+
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFormatMarkdown(t *testing.T) {
+	md := FormatMarkdown(Wrap(WrapWithFields(NotFound("no user"), Fields{"user": 42}), "loading"))
+
+	for _, want := range []string{
+		"**Error:** loading: not found\n",
+		"\n**Kind:** not found\n",
+		"\n- `user`: 42\n",
+		"\n- `message`: no user\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in:\n%s", want, md)
+		}
+	}
+	fence := regexp.MustCompile("\n```\n[^`]*\\.TestFormatMarkdown\n\t[^`]*/markdown_test\\.go:\\d+\n[^`]*```\n")
+	if !fence.MatchString(md) {
+		t.Errorf("expected the stack trace in a code fence, got:\n%s", md)
+	}
+	if got := FormatMarkdown(nil); got != "" {
+		t.Errorf("expected an empty string, got %q", got)
+	}
+}
+
+func TestFormatMarkdownThroughMultiError(t *testing.T) {
+	err := Wrap(multiError{
+		WrapWithFields(New("first"), Fields{"shard": 1}),
+		NotFound("second"),
+	}, "syncing")
+	md := FormatMarkdown(err)
+
+	for _, want := range []string{
+		"\n**Branches:** 2\n",
+		"\nfirst\n",
+		"\n- `shard`: 1\n",
+		"\n**Kind:** not found\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in:\n%s", want, md)
+		}
+	}
+}
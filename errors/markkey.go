@@ -0,0 +1,35 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// MarkKey returns a string that identifies the "shape" of err: the
+// type of its root cause, its kind and the location where it was
+// created. The message is not included, as it often contains
+// variable data. Errors created at the same place in the code thus
+// have the same key, which makes it suitable as a Go map key to
+// aggregate occurrences of identical errors.
+func MarkKey(err error) string {
+	if err == nil {
+		return ""
+	}
+	file, line, _, _ := GetOneLineSource(err)
+
+	return fmt.Sprintf("%s|%s|%s:%d", RootTypeName(err), GetKind(err), file, line)
+}
+
+// RootTypeName returns the name of the Go type of the root cause of
+// err, as printed by %T, e.g. "*net.OpError". This is useful to
+// bucket failures by their origin, e.g. in metrics. For errors
+// decoded with FromJSON(), this is the name of the type of the
+// original error. Returns "" if err is nil.
+func RootTypeName(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return errbase.TypeName(errbase.UnwrapAll(err))
+}
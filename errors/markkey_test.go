@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMarkKey(t *testing.T) {
+	var keys []string
+	for i := 0; i < 2; i++ {
+		keys = append(keys, MarkKey(NotFound(fmt.Sprintf("no user %d", i))))
+	}
+	other := MarkKey(NotFound("no user 0"))
+
+	if keys[0] != keys[1] {
+		t.Errorf("expected errors from the same line to have the same key, got %q and %q", keys[0], keys[1])
+	}
+	if other == keys[0] {
+		t.Errorf("expected errors from different lines to have different keys, got %q", other)
+	}
+	if MarkKey(nil) != "" {
+		t.Errorf("expected an empty key for nil")
+	}
+}
+
+type rootError struct{}
+
+func (*rootError) Error() string { return "root" }
+
+func TestRootTypeName(t *testing.T) {
+	err := WithHint(WrapWithFields(Wrap(&rootError{}, "loading"), Fields{"user": 42}), "retry")
+
+	if got, want := RootTypeName(err), "*errors.rootError"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := RootTypeName(nil); got != "" {
+		t.Errorf("expected an empty name for nil, got %q", got)
+	}
+}
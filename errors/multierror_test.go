@@ -0,0 +1,18 @@
+package errors
+
+import "strings"
+
+// multiError is a minimal multi-error, like the result of the
+// errors.Join() of Go 1.20, which go.mod does not target.
+type multiError []error
+
+func (m multiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+func (m multiError) Unwrap() []error { return m }
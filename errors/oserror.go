@@ -0,0 +1,25 @@
+package errors
+
+import "os"
+
+// osKindMappings are the kinds inferred by EffectiveKind() for the
+// errors of the os package. They are applied after the mappings
+// registered with RegisterKindMapping(), which can thus override them.
+var osKindMappings = []kindMapping{
+	{os.ErrNotExist, NotFoundKind},
+	{os.ErrPermission, UnauthorizedKind},
+}
+
+// osErrorFields returns the structure of the errors of the os package
+// as fields: "op" and "path" for an *os.PathError, "op" for an
+// *os.SyscallError. Returns nil for other errors.
+func osErrorFields(err error) Fields {
+	switch e := err.(type) {
+	case *os.PathError:
+		return Fields{"op": e.Op, "path": e.Path}
+	case *os.SyscallError:
+		return Fields{"op": e.Syscall}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOSPathError(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	_, openErr := os.Open(missing)
+	if _, ok := openErr.(*os.PathError); !ok {
+		t.Fatalf("expected an *os.PathError, got %T", openErr)
+	}
+	err := Wrap(openErr, "loading config")
+
+	if kind := EffectiveKind(err); kind != NotFoundKind {
+		t.Errorf("expected kind %v, got %v", NotFoundKind, kind)
+	}
+	if path, ok := GetField(err, "path"); !ok || path != missing {
+		t.Errorf("expected path %q, got %v", missing, path)
+	}
+	if op, ok := GetField(err, "op"); !ok || op != "open" {
+		t.Errorf("expected op %q, got %v", "open", op)
+	}
+}
+
+func TestOSSyscallError(t *testing.T) {
+	err := Wrap(os.NewSyscallError("chmod", os.ErrPermission), "fixing permissions")
+
+	if kind := EffectiveKind(err); kind != UnauthorizedKind {
+		t.Errorf("expected kind %v, got %v", UnauthorizedKind, kind)
+	}
+	if op, ok := GetField(err, "op"); !ok || op != "chmod" {
+		t.Errorf("expected op %q, got %v", "chmod", op)
+	}
+}
@@ -0,0 +1,30 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errutil"
+
+// FromPanic converts a value recovered from a panic to an error of
+// kind InternalKind, with a stack trace that includes the location of
+// the panic. It is meant to be called from a deferred function:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			err = errors.FromPanic(r)
+//		}
+//	}()
+//
+// If the value is an error, it becomes the cause of the result, with
+// the prefix "panic". Otherwise the message of the result is
+// "panic: <value>". Returns nil if r is nil.
+func FromPanic(r interface{}) error {
+	if r == nil {
+		return nil
+	}
+	var err error
+	if e, ok := r.(error); ok {
+		err = errutil.WrapWithDepth(1, e, "panic")
+	} else {
+		err = errutil.NewWithDepthf(1, "panic: %v", r)
+	}
+
+	return khanWrapWithFieldsAndDepth(InternalKind, err, nil, 1)
+}
@@ -0,0 +1,152 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FlatError is the structure of an error reconstructed from its %+v
+// rendering by ParseFormatted().
+type FlatError struct {
+	// Message is the complete error message, on the first line(s).
+	Message string
+	// Layers are the entries "(1)", "Wraps: (2)", etc, from the
+	// outermost to the innermost.
+	Layers []FlatLayer
+	// Elided is the number of layers that were not rendered, see
+	// SetMaxFormattedLayers().
+	Elided int
+}
+
+// FlatLayer is one layer of a FlatError.
+type FlatLayer struct {
+	// Type is the Go type of the layer, from the "Error types:"
+	// trailer, if present.
+	Type string
+	// Head is the text on the line of the entry, after "(N)".
+	Head string
+	// Details are the lines of detail that follow, stack trace
+	// excluded.
+	Details []string
+	// Kind is the kind of the layer, if it is a Khan error.
+	Kind string
+	// Stack is the stack trace of the layer, if any. The frames that
+	// were elided because they repeat the stack of a layer below are
+	// not included.
+	Stack []FlatFrame
+}
+
+// FlatFrame is one frame of the stack trace of a FlatLayer.
+type FlatFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+var (
+	// entryRe matches the first line of an entry: "(1) head" or, with
+	// any header (see SetWrapsHeader), "Wraps: (2) head".
+	entryRe = regexp.MustCompile(`^(?:\S.* )?\((\d+)\)(?: (.*))?$`)
+	// elidedRe matches the marker for elided layers.
+	elidedRe = regexp.MustCompile(`^\.\.\. \((\d+) more layers elided\)$`)
+	// typeRe matches one of the types in the "Error types:" trailer.
+	typeRe = regexp.MustCompile(`\((\d+)\) (\S+)`)
+)
+
+const (
+	detailPrefix  = "  | "
+	stackHeader   = "  -- stack trace:"
+	elidedFrames  = "[...repeated from below...]"
+	kindPrefix    = "kind: "
+	typesTrailer  = "Error types:"
+	messageMarker = "(1)"
+)
+
+// ParseFormatted parses the %+v rendering of an error, e.g. found in
+// logs, back into its structure. Missing sections, e.g. the stack
+// traces or the "Error types:" trailer, are tolerated. An error is
+// returned only if s does not look like a %+v rendering at all.
+func ParseFormatted(s string) (*FlatError, error) {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	start := -1
+	for i, line := range lines {
+		if line == messageMarker || strings.HasPrefix(line, messageMarker+" ") {
+			start = i
+
+			break
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("no %q entry found: not a detailed error rendering", messageMarker)
+	}
+
+	fe := &FlatError{Message: strings.Join(lines[:start], "\n")}
+	var cur *FlatLayer
+	inStack := false
+	for _, line := range lines[start:] {
+		if m := entryRe.FindStringSubmatch(line); m != nil && m[1] == strconv.Itoa(len(fe.Layers)+1) {
+			fe.Layers = append(fe.Layers, FlatLayer{Head: m[2]})
+			cur = &fe.Layers[len(fe.Layers)-1]
+			cur.Kind = parseKind(cur.Head)
+			inStack = false
+
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, typesTrailer):
+			for _, m := range typeRe.FindAllStringSubmatch(line, -1) {
+				if n, _ := strconv.Atoi(m[1]); n >= 1 && n <= len(fe.Layers) {
+					fe.Layers[n-1].Type = m[2]
+				}
+			}
+		case elidedRe.MatchString(line):
+			fe.Elided, _ = strconv.Atoi(elidedRe.FindStringSubmatch(line)[1])
+		case line == stackHeader:
+			inStack = true
+		case inStack && strings.HasPrefix(line, detailPrefix):
+			parseFrameLine(cur, strings.TrimPrefix(line, detailPrefix))
+		default:
+			detail := strings.TrimPrefix(line, detailPrefix)
+			cur.Details = append(cur.Details, detail)
+			if cur.Kind == "" {
+				cur.Kind = parseKind(detail)
+			}
+		}
+	}
+
+	return fe, nil
+}
+
+// parseKind returns the kind printed on line by a Khan error, if any.
+func parseKind(line string) string {
+	if strings.HasPrefix(line, kindPrefix) {
+		return strings.TrimPrefix(line, kindPrefix)
+	}
+
+	return ""
+}
+
+// parseFrameLine adds the information on one line of a stack trace
+// to the stack of l. Function names and locations alternate; the
+// latter are indented with a tab.
+func parseFrameLine(l *FlatLayer, line string) {
+	switch {
+	case line == elidedFrames:
+	case strings.HasPrefix(line, "\t"):
+		if len(l.Stack) == 0 {
+			return
+		}
+		loc := strings.TrimPrefix(line, "\t")
+		f := &l.Stack[len(l.Stack)-1]
+		f.File = loc
+		if i := strings.LastIndexByte(loc, ':'); i >= 0 {
+			if n, err := strconv.Atoi(loc[i+1:]); err == nil {
+				f.File, f.Line = loc[:i], n
+			}
+		}
+	default:
+		l.Stack = append(l.Stack, FlatFrame{Function: line})
+	}
+}
@@ -0,0 +1,44 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseFormattedRoundTrip(t *testing.T) {
+	err, errLine := Wrap(NotFound("no user", Fields{"user": 42}), "loading"), line()
+
+	flat, parseErr := ParseFormatted(fmt.Sprintf("%+v", err))
+	if parseErr != nil {
+		t.Fatal(parseErr)
+	}
+	if flat.Message != err.Error() {
+		t.Errorf("expected message %q, got %q", err.Error(), flat.Message)
+	}
+	var types []string
+	for _, l := range flat.Layers {
+		types = append(types, l.Type)
+	}
+	if want := ListWrappers(err); !reflect.DeepEqual(types, want) {
+		t.Fatalf("expected layers %q, got %q", want, types)
+	}
+	if l := flat.Layers[1]; l.Head != "loading" {
+		t.Errorf("expected the prefix as head of layer 2, got %q", l.Head)
+	}
+	khan := flat.Layers[2]
+	if khan.Kind != "not found" {
+		t.Errorf("expected the kind of layer 3, got %q", khan.Kind)
+	}
+	if want := []string{"fields: [message:no user, user:42]"}; !reflect.DeepEqual(khan.Details, want) {
+		t.Errorf("expected details %q, got %q", want, khan.Details)
+	}
+	if len(khan.Stack) == 0 || khan.Stack[0].Line != errLine ||
+		khan.Stack[0].Function != "github.com/StevenACoffman/anotherr/errors.TestParseFormattedRoundTrip" {
+		t.Errorf("expected the stack of layer 3 to start at line %d, got %+v", errLine, khan.Stack)
+	}
+
+	if _, parseErr := ParseFormatted("not an error rendering"); parseErr == nil {
+		t.Errorf("expected an error for text that is not a %%+v rendering")
+	}
+}
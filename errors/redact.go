@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// redactedValue replaces the field values masked as per the patterns
+// registered with RegisterRedactionPattern().
+const redactedValue = "<redacted>"
+
+// redactionPatterns is the list of the patterns registered with
+// RegisterRedactionPattern().
+var redactionPatterns = struct {
+	errbase.Registry
+	patterns []*regexp.Regexp
+}{}
+
+// RegisterRedactionPattern declares that the field values whose
+// rendering with %v matches re are sensitive, e.g. email addresses or
+// credit card numbers. Such values are printed as "<redacted>" when
+// formatting an error and in its safe details (see GetSafeDetails),
+// whatever the field they were put in, to catch the PII that slips
+// into arbitrary fields:
+//
+//	errors.RegisterRedactionPattern(regexp.MustCompile(`[^@\s]+@[^@\s]+`))
+//
+// The values remain unchanged in the error, e.g. for GetFields().
+//
+// This should be called at init time, but is safe to call
+// concurrently with the formatting of errors.
+func RegisterRedactionPattern(re *regexp.Regexp) {
+	redactionPatterns.Update(func() {
+		redactionPatterns.patterns = append(redactionPatterns.patterns, re)
+	})
+}
+
+// redactFieldValue returns v, or redactedValue if the rendering of v
+// matches one of the patterns registered with
+// RegisterRedactionPattern().
+func redactFieldValue(v interface{}) interface{} {
+	var patterns []*regexp.Regexp
+	redactionPatterns.Read(func() { patterns = redactionPatterns.patterns })
+	if len(patterns) == 0 || v == nil {
+		return v
+	}
+	s := fmt.Sprintf("%v", v)
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return redactedValue
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,34 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRegisterRedactionPattern(t *testing.T) {
+	var saved []*regexp.Regexp
+	redactionPatterns.Read(func() { saved = redactionPatterns.patterns })
+	defer redactionPatterns.Update(func() { redactionPatterns.patterns = saved })
+
+	err := WrapWithFields(New("boom"), Fields{"contact": "bob@example.com", "user": 42})
+	err = WithSafeFields(err, Fields{"owner": "alice@example.com"})
+	RegisterRedactionPattern(regexp.MustCompile(`[^@\s]+@[^@\s]+`))
+
+	verbose := fmt.Sprintf("%+v", err)
+	if strings.Contains(verbose, "@example.com") {
+		t.Errorf("expected the emails masked, got:\n%s", verbose)
+	}
+	for _, want := range []string{"contact:" + redactedValue, "user:42"} {
+		if !strings.Contains(verbose, want) {
+			t.Errorf("expected %q, got:\n%s", want, verbose)
+		}
+	}
+	if details := GetSafeDetails(err); !containsString(details, "owner:"+redactedValue) {
+		t.Errorf("expected the email masked in the safe details, got %q", details)
+	}
+	if v, _ := GetField(err, "contact"); v != "bob@example.com" {
+		t.Errorf("expected the value unchanged in the error, got %v", v)
+	}
+}
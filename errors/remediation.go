@@ -0,0 +1,32 @@
+package errors
+
+// remediationField is the field holding the action set by
+// WithRemediation.
+const remediationField = "remediation"
+
+// WithRemediation records the action recommended to recover from err,
+// e.g. "retry with exponential backoff" or "contact support", as a
+// field in a new layer around err. Unlike a hint (see WithHint), which
+// is meant for the end user, the remediation is meant for operators
+// and automation. If err is nil, WithRemediation returns nil.
+func WithRemediation(err error, action string) error {
+	if err == nil {
+		return nil
+	}
+
+	return WrapWithFieldsAndDepth(err, Fields{remediationField: action}, 1)
+}
+
+// GetRemediation returns the action recorded by WithRemediation on
+// err, or on any of its causes. When there are several, the outermost
+// wins, so that a caller can refine the recommendation of the errors
+// it receives.
+func GetRemediation(err error) (string, bool) {
+	action, ok := GetField(err, remediationField)
+	if !ok {
+		return "", false
+	}
+	s, ok := action.(string)
+
+	return s, ok
+}
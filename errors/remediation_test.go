@@ -0,0 +1,23 @@
+package errors
+
+import "testing"
+
+func TestGetRemediation(t *testing.T) {
+	err := WithRemediation(TransientService(New("timeout")), "retry with exponential backoff")
+	err = Wrap(err, "calling billing")
+	if got, ok := GetRemediation(err); !ok || got != "retry with exponential backoff" {
+		t.Errorf("expected the remediation through wrapping, got %q, %v", got, ok)
+	}
+
+	err = WithRemediation(err, "contact support")
+	if got, _ := GetRemediation(err); got != "contact support" {
+		t.Errorf("expected the outermost remediation, got %q", got)
+	}
+
+	if got, ok := GetRemediation(New("boom")); ok || got != "" {
+		t.Errorf("expected no remediation, got %q, %v", got, ok)
+	}
+	if WithRemediation(nil, "retry") != nil {
+		t.Error("expected nil")
+	}
+}
@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// IsRemote returns true if err, or any of its causes, was decoded with
+// FromJSON() or UnmarshalBinary(), i.e. crossed a process boundary.
+// Handlers can use this to avoid, e.g., reporting to Sentry an error
+// that the process where it originated already reported.
+func IsRemote(err error) bool {
+	return !errbase.Walk(err, func(c error) bool {
+		_, ok := c.(*remoteError)
+
+		return !ok
+	})
+}
+
+// markRemote tags err, freshly decoded, as remote. See IsRemote.
+func markRemote(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &remoteError{cause: err}
+}
+
+// remoteError is the wrapper added around the errors decoded from
+// the wire. It is not encoded itself: an error decoded and encoded
+// again is only tagged once when it is decoded the second time.
+type remoteError struct {
+	cause error
+}
+
+var (
+	_ error         = (*remoteError)(nil)
+	_ fmt.Formatter = (*remoteError)(nil)
+)
+
+func (w *remoteError) Error() string { return w.cause.Error() }
+func (w *remoteError) Cause() error  { return w.cause }
+func (w *remoteError) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *remoteError) Format(s fmt.State, verb rune) { errbase.FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *remoteError) SafeFormatError(p errbase.Printer) (next error) {
+	if p.Detail() {
+		p.Print("decoded remote error")
+	}
+
+	return w.cause
+}
+
+func init() {
+	errbase.RegisterRewrapper((*remoteError)(nil), func(_, newCause error) error {
+		return &remoteError{cause: newCause}
+	})
+}
@@ -0,0 +1,41 @@
+package errors
+
+import "testing"
+
+func TestIsRemote(t *testing.T) {
+	err := Wrap(NotFound("no user"), "loading")
+	if IsRemote(err) {
+		t.Error("expected a local error not to be remote")
+	}
+
+	data, encErr := ToJSON(err)
+	if encErr != nil {
+		t.Fatal(encErr)
+	}
+	fromJSON, decErr := FromJSON(data)
+	if decErr != nil {
+		t.Fatal(decErr)
+	}
+	if !IsRemote(fromJSON) {
+		t.Error("expected an error decoded from JSON to be remote")
+	}
+	if !IsRemote(Wrap(fromJSON, "handling")) {
+		t.Error("expected a wrapped decoded error to be remote")
+	}
+
+	data, encErr = MarshalBinary(err)
+	if encErr != nil {
+		t.Fatal(encErr)
+	}
+	fromBinary, decErr := UnmarshalBinary(data)
+	if decErr != nil {
+		t.Fatal(decErr)
+	}
+	if !IsRemote(fromBinary) {
+		t.Error("expected an error decoded from binary to be remote")
+	}
+
+	if IsRemote(nil) {
+		t.Error("expected nil not to be remote")
+	}
+}
@@ -0,0 +1,159 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// reportableKinds is the set of kinds of the errors that are worth
+// reporting, e.g. to Sentry. See ShouldReport().
+var reportableKinds = struct {
+	errbase.Registry
+	kinds map[errorKind]bool
+}{kinds: map[errorKind]bool{
+	NotFoundKind:        true,
+	InvalidInputKind:    true,
+	NotAllowedKind:      true,
+	UnauthorizedKind:    true,
+	InternalKind:        true,
+	NotImplementedKind:  true,
+	GraphqlResponseKind: true,
+	KhanServiceKind:     true,
+	ServiceKind:         true,
+	UnspecifiedKind:     true,
+}}
+
+// SetReportableKinds replaces the set of kinds of the errors for
+// which ShouldReport() returns true. By default, all the kinds are
+// reportable except TransientKhanServiceKind and TransientServiceKind,
+// since those failures are expected to go away on retry. Include
+// UnspecifiedKind to report the errors that have no kind.
+//
+// This should be called at init time, but is safe to call
+// concurrently with ShouldReport().
+func SetReportableKinds(kinds ...ErrorKind) {
+	m := make(map[errorKind]bool, len(kinds))
+	for _, k := range kinds {
+		m[k] = true
+	}
+	reportableKinds.Update(func() { reportableKinds.kinds = m })
+}
+
+// ShouldReport returns true if err is worth reporting, e.g. to
+// Sentry, based on its kind (see EffectiveKind). This lets reporting
+// middleware share a single definition of what is alert-worthy.
+// See SetReportableKinds() for the default. Returns false if err is
+// nil.
+func ShouldReport(err error) bool {
+	if err == nil {
+		return false
+	}
+	kind := EffectiveKind(err)
+	var reportable bool
+	reportableKinds.Read(func() { reportable = reportableKinds.kinds[kind] })
+
+	return reportable
+}
+
+// expectedKinds is the set of kinds of the errors that are normal
+// business outcomes rather than bugs. See IsExpected().
+var expectedKinds = struct {
+	errbase.Registry
+	kinds map[errorKind]bool
+}{kinds: map[errorKind]bool{
+	NotFoundKind:     true,
+	InvalidInputKind: true,
+	NotAllowedKind:   true,
+	UnauthorizedKind: true,
+}}
+
+// SetExpectedKinds replaces the set of kinds of the errors for which
+// IsExpected() returns true. By default, these are NotFoundKind,
+// InvalidInputKind, NotAllowedKind and UnauthorizedKind.
+//
+// This should be called at init time, but is safe to call
+// concurrently with IsExpected().
+func SetExpectedKinds(kinds ...ErrorKind) {
+	m := make(map[errorKind]bool, len(kinds))
+	for _, k := range kinds {
+		m[k] = true
+	}
+	expectedKinds.Update(func() { expectedKinds.kinds = m })
+}
+
+// IsExpected returns true if err is a normal business outcome, e.g. a
+// record that was not found, rather than a bug, based on its kind (see
+// EffectiveKind and SetExpectedKinds). An assertion failure (see
+// HasAssertionFailure) is never expected, whatever its kind. This lets
+// alerting decide whether to page someone. Returns false if err is
+// nil.
+func IsExpected(err error) bool {
+	if err == nil || HasAssertionFailure(err) {
+		return false
+	}
+	kind := EffectiveKind(err)
+	var expected bool
+	expectedKinds.Read(func() { expected = expectedKinds.kinds[kind] })
+
+	return expected
+}
+
+// Reporter is implemented by the backends that errors are reported
+// to, e.g. Sentry, Datadog or Rollbar. See SetReporter().
+type Reporter interface {
+	// Report sends err to the backend. It is called from the goroutine
+	// that calls Report(), so it should not block for long.
+	Report(err error)
+}
+
+// noopReporter is the default Reporter: it discards all the errors.
+type noopReporter struct{}
+
+func (noopReporter) Report(error) {}
+
+// reporter is the Reporter used by Report().
+var reporter = struct {
+	errbase.Registry
+	r Reporter
+}{r: noopReporter{}}
+
+// SetReporter sets the backend that Report() sends errors to. By
+// default, the errors are discarded. Passing nil restores the
+// default.
+//
+// This should be called at init time, but is safe to call
+// concurrently with Report().
+func SetReporter(r Reporter) {
+	if r == nil {
+		r = noopReporter{}
+	}
+	reporter.Update(func() { reporter.r = r })
+}
+
+// Report sends err to the Reporter set with SetReporter() if it is
+// worth reporting, as per ShouldReport(). This keeps the code that
+// handles errors independent of the reporting vendor.
+func Report(err error) {
+	if !ShouldReport(err) {
+		return
+	}
+	var r Reporter
+	reporter.Read(func() { r = reporter.r })
+	r.Report(err)
+}
+
+// Title returns a concise title for err, for the grouping of errors by
+// trackers such as Sentry: its kind (see EffectiveKind) and the
+// function, and file, where it originated (see GetOneLineSource), e.g.
+// "not found in loadUser (user.go)". Unlike the message, the title
+// has no variable parts, so that "user 42 not found" and "user 99 not
+// found" share it. The line number is left out, so that the title
+// survives unrelated edits of the file. Returns "" if err is nil.
+func Title(err error) string {
+	if err == nil {
+		return ""
+	}
+	title := EffectiveKind(err).String()
+	if file, _, fn, ok := GetOneLineSource(err); ok {
+		title += " in " + fn + " (" + file + ")"
+	}
+
+	return title
+}
@@ -0,0 +1,114 @@
+package errors
+
+import "testing"
+
+func TestShouldReportDefaults(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{TransientService(New("timeout")), false},
+		{Wrap(TransientKhanService(New("unavailable")), "loading"), false},
+		{Internal(New("bug")), true},
+		{Wrap(Internal(New("bug")), "loading"), true},
+		{nil, false},
+	} {
+		if got := ShouldReport(tc.err); got != tc.want {
+			t.Errorf("ShouldReport(%v): expected %v, got %v", tc.err, tc.want, got)
+		}
+	}
+}
+
+func TestSetReportableKinds(t *testing.T) {
+	var saved map[errorKind]bool
+	reportableKinds.Read(func() { saved = reportableKinds.kinds })
+	defer reportableKinds.Update(func() { reportableKinds.kinds = saved })
+
+	SetReportableKinds(TransientServiceKind)
+	if !ShouldReport(TransientService(New("timeout"))) {
+		t.Errorf("expected a transient error to be reportable")
+	}
+	if ShouldReport(Internal(New("bug"))) {
+		t.Errorf("expected an internal error not to be reportable")
+	}
+}
+
+// fakeReporter records the errors reported to it.
+type fakeReporter struct{ reported []error }
+
+func (r *fakeReporter) Report(err error) { r.reported = append(r.reported, err) }
+
+func TestReport(t *testing.T) {
+	r := &fakeReporter{}
+	SetReporter(r)
+	defer SetReporter(nil)
+
+	bug := Internal(New("bug"))
+	Report(TransientService(New("timeout")))
+	Report(bug)
+	Report(nil)
+	if len(r.reported) != 1 || r.reported[0] != bug {
+		t.Errorf("expected only the internal error reported, got %v", r.reported)
+	}
+
+	// The default reporter discards the errors.
+	SetReporter(nil)
+	Report(bug)
+	if len(r.reported) != 1 {
+		t.Errorf("expected no report after the reporter was reset, got %v", r.reported)
+	}
+}
+
+func TestIsExpected(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{NotFound("no user"), true},
+		{Wrap(InvalidInput("bad id"), "loading"), true},
+		{Internal(New("bug")), false},
+		{AssertionFailedf("bug"), false},
+		{NotFound(AssertionFailedf("bug")), false},
+		{nil, false},
+	} {
+		if got := IsExpected(tc.err); got != tc.want {
+			t.Errorf("IsExpected(%v): expected %v, got %v", tc.err, tc.want, got)
+		}
+	}
+}
+
+func TestSetExpectedKinds(t *testing.T) {
+	var saved map[errorKind]bool
+	expectedKinds.Read(func() { saved = expectedKinds.kinds })
+	defer expectedKinds.Update(func() { expectedKinds.kinds = saved })
+
+	SetExpectedKinds(TransientServiceKind)
+	if !IsExpected(TransientService(New("timeout"))) {
+		t.Errorf("expected a transient error to be expected")
+	}
+	if IsExpected(NotFound("no user")) {
+		t.Errorf("expected a not found error not to be expected")
+	}
+}
+
+// loadUser fails like a lookup of user id would.
+func loadUser(id int) error {
+	return NotFound(Fields{"user": id})
+}
+
+func TestTitle(t *testing.T) {
+	a, b := loadUser(42), Wrap(loadUser(99), "rendering profile")
+
+	if Title(a) != Title(b) {
+		t.Errorf("expected the same title, got %q and %q", Title(a), Title(b))
+	}
+	if want := "not found in loadUser (report_test.go)"; Title(a) != want {
+		t.Errorf("expected %q, got %q", want, Title(a))
+	}
+	if Title(Internal(New("bug"))) == Title(a) {
+		t.Errorf("expected a different title for a different kind")
+	}
+	if Title(nil) != "" {
+		t.Errorf("expected an empty title for nil")
+	}
+}
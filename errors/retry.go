@@ -0,0 +1,38 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// retryCountField is the field holding the count maintained by
+// IncrementRetryCount.
+const retryCountField = "retry_count"
+
+// IncrementRetryCount records that the operation that failed with err
+// was retried once more, so that the final error can be logged as
+// "failed after N retries". The count is stored as a field, in a new
+// layer around err. If err is nil, IncrementRetryCount returns nil.
+func IncrementRetryCount(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return WrapWithFieldsAndDepth(err, Fields{retryCountField: GetRetryCount(err) + 1}, 1)
+}
+
+// GetRetryCount returns the number of times IncrementRetryCount was
+// called on err, or on any of its causes. Since each call stores the
+// updated count, the outermost count is the current one. Returns 0 if
+// the count was never incremented. The count is also found after a
+// round trip through ToJSON and FromJSON.
+func GetRetryCount(err error) int {
+	count := 0
+	errbase.Walk(err, func(c error) bool {
+		n, ok := intFieldValue(layerFields(c)[retryCountField])
+		if ok {
+			count = n
+		}
+
+		return !ok
+	})
+
+	return count
+}
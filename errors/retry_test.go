@@ -0,0 +1,41 @@
+package errors
+
+import "testing"
+
+func TestRetryCount(t *testing.T) {
+	err := New("unavailable")
+	if n := GetRetryCount(err); n != 0 {
+		t.Errorf("expected 0 retries, got %d", n)
+	}
+	for i := 0; i < 3; i++ {
+		err = Wrap(IncrementRetryCount(err), "attempt")
+	}
+
+	if n := GetRetryCount(err); n != 3 {
+		t.Errorf("expected 3 retries, got %d", n)
+	}
+	if v, ok := GetField(err, "retry_count"); !ok || v != 3 {
+		t.Errorf("expected the merged field to be 3, got %v", v)
+	}
+	if IncrementRetryCount(nil) != nil {
+		t.Errorf("expected nil for a nil error")
+	}
+}
+
+func TestRetryCountAfterJSON(t *testing.T) {
+	err := IncrementRetryCount(IncrementRetryCount(New("unavailable")))
+	data, encErr := ToJSON(err)
+	if encErr != nil {
+		t.Fatal(encErr)
+	}
+	decoded, decErr := FromJSON(data)
+	if decErr != nil {
+		t.Fatal(decErr)
+	}
+	if n := GetRetryCount(decoded); n != 2 {
+		t.Errorf("expected 2 retries after decoding, got %d", n)
+	}
+	if n := GetRetryCount(IncrementRetryCount(decoded)); n != 3 {
+		t.Errorf("expected 3 retries after incrementing the decoded error, got %d", n)
+	}
+}
@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// maxPayloadSize is the maximum size, in bytes, of the rendering of
+// the request and response attached with WithRequestResponse.
+const maxPayloadSize = 1024
+
+// WithRequestResponse annotates err, typically the failure of a
+// call to another service, with the request that was sent and the
+// response that was received, for debugging. Both are rendered with
+// %+v when attached, and truncated to 1KB.
+//
+// The request and response are considered unsafe: they may contain
+// PII. They are shown when formatting err with %+v, but are excluded
+// from GetSafeDetails() and thus from Sentry reports.
+// If err is nil, WithRequestResponse returns nil.
+func WithRequestResponse(err error, request, response interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withRequestResponse{
+		cause:    err,
+		request:  renderPayload(request),
+		response: renderPayload(response),
+	}
+}
+
+// GetRequestResponse returns the renderings of the request and
+// response attached to err with WithRequestResponse(). If there are
+// several, the outermost one wins.
+func GetRequestResponse(err error) (request, response string, ok bool) {
+	errbase.Walk(err, func(c error) bool {
+		if w, isRR := c.(*withRequestResponse); isRR {
+			request, response, ok = w.request, w.response, true
+		}
+
+		return !ok
+	})
+
+	return request, response, ok
+}
+
+func renderPayload(v interface{}) string {
+	s := fmt.Sprintf("%+v", v)
+	if len(s) > maxPayloadSize {
+		// Cut on a rune boundary, so as not to turn a valid UTF-8
+		// rendering into an invalid one.
+		n := maxPayloadSize
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n] + "... (truncated)"
+	}
+
+	return s
+}
+
+// withRequestResponse is an error wrapper that carries the request
+// and response of a failed call. It deliberately does not implement
+// errbase.SafeDetailer.
+type withRequestResponse struct {
+	cause    error
+	request  string
+	response string
+}
+
+var (
+	_ error         = (*withRequestResponse)(nil)
+	_ fmt.Formatter = (*withRequestResponse)(nil)
+)
+
+func (w *withRequestResponse) Error() string { return w.cause.Error() }
+func (w *withRequestResponse) Cause() error  { return w.cause }
+func (w *withRequestResponse) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *withRequestResponse) Format(s fmt.State, verb rune) { errbase.FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *withRequestResponse) SafeFormatError(p errbase.Printer) (next error) {
+	if p.Detail() {
+		p.Printf("request: %s\nresponse: %s", w.request, w.response)
+	}
+
+	return w.cause
+}
+
+func init() {
+	errbase.RegisterRewrapper((*withRequestResponse)(nil), func(err, newCause error) error {
+		w := *(err.(*withRequestResponse))
+		w.cause = newCause
+
+		return &w
+	})
+}
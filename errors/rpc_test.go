@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWithRequestResponse(t *testing.T) {
+	err := Wrap(WithRequestResponse(New("bad gateway"), map[string]string{"email": "bob@example.com"}, "body: secret-token"), "calling billing")
+
+	verbose := fmt.Sprintf("%+v", err)
+	details := strings.Join(GetSafeDetails(err), "\n")
+	for _, s := range []string{"bob@example.com", "secret-token"} {
+		if !strings.Contains(verbose, s) {
+			t.Errorf("expected %q in %%+v, got:\n%s", s, verbose)
+		}
+		if strings.Contains(details, s) {
+			t.Errorf("%q leaked into the safe details:\n%s", s, details)
+		}
+	}
+	if _, resp, ok := GetRequestResponse(err); !ok || resp != "body: secret-token" {
+		t.Errorf("expected the response, got %q, %v", resp, ok)
+	}
+}
+
+func TestWithRequestResponseTruncatesOnRuneBoundary(t *testing.T) {
+	// 'é' is 2 bytes long, so the limit falls in the middle of a rune.
+	payload := "x" + strings.Repeat("é", maxPayloadSize)
+	req, _, _ := GetRequestResponse(WithRequestResponse(New("x"), payload, nil))
+
+	if !strings.HasSuffix(req, "... (truncated)") || len(req) > maxPayloadSize+len("... (truncated)") {
+		t.Errorf("expected a truncated payload, got %d bytes", len(req))
+	}
+	if !utf8.ValidString(req) {
+		t.Errorf("expected valid UTF-8 after truncation")
+	}
+}
@@ -0,0 +1,12 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// SafeDetailer is implemented by error leaf or wrapper types that can
+// provide details that are safe to report, i.e. free of PII.
+type SafeDetailer = errbase.SafeDetailer
+
+// GetSafeDetails collects the safe details of err and all its causes,
+// from the outermost to the innermost. These are the details that can
+// be included in reports, e.g. to Sentry.
+func GetSafeDetails(err error) []string { return errbase.GetSafeDetails(err) }
@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// WithSafeFields annotates err with fields that the caller asserts
+// contain no PII, e.g. an HTTP status code or a resource type. Unlike
+// the fields attached with WrapWithFields(), which may only be shown
+// to developers, these are included verbatim in the safe details of
+// err, and thus in Sentry reports. They are otherwise regular fields,
+// returned by GetField(), GetAllFields(), etc.
+// If err is nil, WithSafeFields returns nil.
+//
+// Detail is shown:
+// - via `errors.GetSafeDetails()`.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func WithSafeFields(err error, fields Fields) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withSafeFields{cause: err, fields: fields}
+}
+
+// withSafeFields is an error wrapper that carries fields safe for
+// reporting.
+type withSafeFields struct {
+	cause  error
+	fields Fields
+}
+
+var (
+	_ error         = (*withSafeFields)(nil)
+	_ fmt.Formatter = (*withSafeFields)(nil)
+)
+
+func (w *withSafeFields) Error() string { return w.cause.Error() }
+func (w *withSafeFields) Cause() error  { return w.cause }
+func (w *withSafeFields) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *withSafeFields) Format(s fmt.State, verb rune) { errbase.FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *withSafeFields) SafeFormatError(p errbase.Printer) (next error) {
+	if errbase.WantsInlineDetail(p) {
+		printInlineFields(p, w.fields)
+	}
+	if p.Detail() && len(w.fields) != 0 {
+		p.Printf("safe ")
+		printFields(p, w.fields)
+	}
+
+	return w.cause
+}
+
+// SafeDetails implements the errbase.SafeDetailer interface. Each
+// field is rendered as "key:value", sorted by key.
+func (w *withSafeFields) SafeDetails() []string {
+	var res []string
+	fieldsIterate(w.fields, func(_ int, s string) {
+		res = append(res, s)
+	})
+
+	return res
+}
+
+func init() {
+	errbase.RegisterRewrapper((*withSafeFields)(nil), func(err, newCause error) error {
+		return &withSafeFields{cause: newCause, fields: err.(*withSafeFields).fields}
+	})
+}
@@ -0,0 +1,42 @@
+package errors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithSafeFields(t *testing.T) {
+	err := WrapWithFields(New("boom"), Fields{"email": "me@example.com"})
+	err = WithSafeFields(err, Fields{"status": 404, "resource": "user"})
+
+	details := GetSafeDetails(err)
+	for _, want := range []string{"resource:user", "status:404"} {
+		if !containsString(details, want) {
+			t.Errorf("expected %q in the safe details, got %q", want, details)
+		}
+	}
+	for _, d := range details {
+		if strings.Contains(d, "me@example.com") {
+			t.Errorf("expected no regular field in the safe details, got %q", details)
+		}
+	}
+
+	want := []Fields{{"status": 404, "resource": "user"}, {"email": "me@example.com"}}
+	if got := GetAllFields(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if WithSafeFields(nil, Fields{"status": 404}) != nil {
+		t.Error("expected nil")
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secondary
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// WithSecondaryError enhances the error given as first argument with
+// an annotation that carries the error given as second argument.  The
+// second error does not participate in cause analysis (Is, etc) and
+// is only revealed when printing out the error or collecting safe
+// (PII-free) details for reporting.
+//
+// If additionalErr is nil, the first error is returned as-is.
+//
+// Detail is shown:
+// - via `errors.GetSafeDetails()`, shows details from secondary error.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func WithSecondaryError(err error, additionalErr error) error {
+	if err == nil || additionalErr == nil {
+		return err
+	}
+
+	return &withSecondaryError{cause: err, secondaryError: additionalErr}
+}
+
+// CombineErrors returns err, or, if err is nil, otherErr.
+// if err is non-nil, otherErr is attached as secondary error.
+// See the documentation of `WithSecondaryError()` for details.
+//
+// In summary:
+// - if both are nil, the result is nil.
+// - if only one is non-nil, it is returned unchanged.
+// - if both are non-nil, the result is err with otherErr attached.
+//
+// This makes it suitable for deferred cleanups, e.g.
+//
+//	defer func() { err = errors.CombineErrors(err, f.Close()) }()
+func CombineErrors(err, otherErr error) error {
+	if err == nil {
+		return otherErr
+	}
+
+	return WithSecondaryError(err, otherErr)
+}
+
+// withSecondaryError is an error wrapper that stores an
+// additional error in the error chain, for display purposes only.
+type withSecondaryError struct {
+	cause          error
+	secondaryError error
+}
+
+var (
+	_ error         = (*withSecondaryError)(nil)
+	_ fmt.Formatter = (*withSecondaryError)(nil)
+)
+
+func (e *withSecondaryError) Error() string { return e.cause.Error() }
+func (e *withSecondaryError) Cause() error  { return e.cause }
+func (e *withSecondaryError) Unwrap() error { return e.cause }
+
+// Format implements the fmt.Formatter interface.
+func (e *withSecondaryError) Format(s fmt.State, verb rune) { errbase.FormatError(e, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (e *withSecondaryError) SafeFormatError(p errbase.Printer) (next error) {
+	if p.Detail() {
+		p.Printf("secondary error attachment\n%+v", e.secondaryError)
+	}
+
+	return e.cause
+}
+
+// SafeDetails implements the errbase.SafeDetailer interface. Only
+// the safe details of the secondary error are reported: its %+v
+// rendering may contain PII.
+func (e *withSecondaryError) SafeDetails() []string {
+	return errbase.GetSafeDetails(e.secondaryError)
+}
+
+func init() {
+	errbase.RegisterRewrapper((*withSecondaryError)(nil), func(err, newCause error) error {
+		return &withSecondaryError{cause: newCause, secondaryError: err.(*withSecondaryError).secondaryError}
+	})
+}
@@ -0,0 +1,30 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/secondary"
+
+// WithSecondaryError enhances the error given as first argument with
+// an annotation that carries the error given as second argument.  The
+// second error does not participate in cause analysis (Is, etc) and
+// is only revealed when printing out the error or collecting safe
+// (PII-free) details for reporting.
+//
+// If additionalErr is nil, the first error is returned as-is.
+//
+// Detail is shown:
+// - via `errors.GetSafeDetails()`, shows details from secondary error.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func WithSecondaryError(err error, additionalErr error) error {
+	return secondary.WithSecondaryError(err, additionalErr)
+}
+
+// CombineErrors returns err, or, if err is nil, otherErr.
+// if err is non-nil, otherErr is attached as secondary error.
+// See the documentation of `WithSecondaryError()` for details.
+//
+// If both are nil, the result is nil, and if only one is non-nil, it
+// is returned unchanged. This makes it suitable for deferred cleanups,
+// e.g. err = errors.CombineErrors(err, f.Close()).
+func CombineErrors(err, otherErr error) error {
+	return secondary.CombineErrors(err, otherErr)
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWrapfAttachesErrorArgs(t *testing.T) {
+	arg := New("connection reset")
+	err := Wrapf(New("query failed"), "while closing: %v", arg)
+
+	verbose := fmt.Sprintf("%+v", err)
+	if !strings.Contains(verbose, "secondary error attachment") {
+		t.Errorf("expected a secondary error attachment in %%+v, got:\n%s", verbose)
+	}
+	if n := strings.Count(verbose, "connection reset"); n < 2 {
+		t.Errorf("expected the error arg in the message and as attachment, found it %d times in:\n%s", n, verbose)
+	}
+	if Is(err, arg) {
+		t.Errorf("the secondary error must not participate in Is()")
+	}
+}
+
+func TestSecondaryErrorSafeDetails(t *testing.T) {
+	inner := WithRequestResponse(New("inner"), map[string]string{"ssn": "123-45-6789"}, "resp-secret")
+	err := WithSecondaryError(New("primary"), inner)
+
+	verbose := fmt.Sprintf("%+v", err)
+	for _, s := range []string{"123-45-6789", "resp-secret"} {
+		if !strings.Contains(verbose, s) {
+			t.Errorf("expected %q in %%+v, got:\n%s", s, verbose)
+		}
+	}
+
+	details := strings.Join(GetSafeDetails(err), "\n")
+	for _, s := range []string{"123-45-6789", "resp-secret"} {
+		if strings.Contains(details, s) {
+			t.Errorf("%q leaked into the safe details:\n%s", s, details)
+		}
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	a := New("write failed")
+	b := New("close failed")
+
+	for _, tc := range []struct {
+		name         string
+		err, other   error
+		want         error
+		wantAttached bool
+	}{
+		{"both nil", nil, nil, nil, false},
+		{"only the first", a, nil, a, false},
+		{"only the second", nil, b, b, false},
+		{"both", a, b, a, true},
+	} {
+		got := CombineErrors(tc.err, tc.other)
+		if !tc.wantAttached {
+			if got != tc.want {
+				t.Errorf("%s: expected %v unchanged, got %v", tc.name, tc.want, got)
+			}
+
+			continue
+		}
+		if Unwrap(got) != tc.want || got.Error() != tc.want.Error() {
+			t.Errorf("%s: expected %v with an attachment, got %v", tc.name, tc.want, got)
+		}
+		if verbose := fmt.Sprintf("%+v", got); !strings.Contains(verbose, "secondary error attachment") ||
+			!strings.Contains(verbose, tc.other.Error()) {
+			t.Errorf("%s: expected %v as secondary error, got:\n%s", tc.name, tc.other, verbose)
+		}
+		if Is(got, tc.other) {
+			t.Errorf("%s: the secondary error must not participate in Is()", tc.name)
+		}
+	}
+}
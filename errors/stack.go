@@ -37,10 +37,25 @@ func (s *stack) StackTrace() errbase.StackTrace {
 
 // callers mirrors the code in github.com/pkg/errors,
 // but makes the depth customizable.
+// See the function of the same name in package withstack for what is
+// deferred to format time.
 func callers(depth int) *stack {
+	if errbase.StacksSuppressed() {
+		return &stack{}
+	}
 	const numFrames = 32
 	var pcs [numFrames]uintptr
 	n := runtime.Callers(2+depth, pcs[:])
+	var st stack = errbase.TrimInternalFrames(pcs[0:n])
+
+	return &st
+}
+
+// caller is like callers, but only captures the single frame
+// at the given depth.
+func caller(depth int) *stack {
+	var pcs [1]uintptr
+	n := runtime.Callers(2+depth, pcs[:])
 	var st stack = pcs[0:n]
 
 	return &st
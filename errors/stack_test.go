@@ -0,0 +1,38 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStackTraceFormatsCallSite(t *testing.T) {
+	err, l := New("x"), line()
+
+	verbose := fmt.Sprintf("%+v", err)
+	for _, want := range []string{
+		"errors.TestStackTraceFormatsCallSite\n",
+		fmt.Sprintf("stack_test.go:%d\n", l),
+	} {
+		if !strings.Contains(verbose, want) {
+			t.Errorf("expected %q in the stack trace:\n%s", want, verbose)
+		}
+	}
+}
+
+// The benchmarks below split the cost of an error between the capture
+// of its stack trace, paid by every error, and the resolution of the
+// stack trace to functions, files and lines, only paid when it is
+// formatted.
+
+func BenchmarkNew(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = New("x")
+	}
+}
+
+func BenchmarkNewFormatted(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = fmt.Sprintf("%+v", New("x"))
+	}
+}
@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// ourPkgPath is the import path of this package. The types defined
+// here and in its subpackages all have import paths starting with it.
+var ourPkgPath = reflect.TypeOf(khanError{}).PkgPath()
+
+// IsOurs returns true if err, or any of its causes, is of a type
+// defined by this package or one of its subpackages. The plain
+// wrappers produced by ToStdError do not count.
+func IsOurs(err error) bool {
+	return !errbase.Walk(err, func(c error) bool { return !isOurType(c) })
+}
+
+func isOurType(err error) bool {
+	switch err.(type) {
+	case *stdOpaqueWrapper, *stdJoinError:
+		return false
+	}
+	t := reflect.TypeOf(err)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pkg := t.PkgPath()
+
+	return pkg == ourPkgPath || strings.HasPrefix(pkg, ourPkgPath+"/")
+}
+
+// ToStdError converts err to a chain of errors that only uses the
+// wrapping provided by the standard library, i.e. fmt.Errorf("%w") or
+// an equivalent plain Unwrap() method,
+// for code that must hand a plain error to a library intolerant of
+// our types. The message of the result is that of err, and the root
+// cause of err is preserved when it is not one of our types, so that
+// the standard errors.Is(), errors.As() and errors.Unwrap() still
+// reach it. Everything else is lost: kinds, fields, stack traces,
+// hints, etc.
+//
+// The multi-errors in the chain, e.g. built with the errors.Join() of
+// the standard library, are rebuilt with the branches converted, so
+// that errors.Is() and errors.As() still reach the root cause of each
+// branch, from Go 1.20 on.
+//
+// If err does not use any of our types (see IsOurs), it is returned
+// as-is.
+func ToStdError(err error) error {
+	if !IsOurs(err) {
+		return err
+	}
+	if m, ok := err.(interface{ Unwrap() []error }); ok {
+		branches := m.Unwrap()
+		errs := make([]error, len(branches))
+		for i, b := range branches {
+			errs[i] = ToStdError(b)
+		}
+
+		return &stdJoinError{msg: err.Error(), errs: errs}
+	}
+	cause := errbase.UnwrapOnce(err)
+	if cause == nil {
+		// fmt.Errorf without %w is equivalent to the standard errors.New().
+		return fmt.Errorf("%s", err.Error())
+	}
+	newCause := ToStdError(cause)
+	msg, causeMsg := err.Error(), cause.Error()
+	switch {
+	case msg == causeMsg:
+		// The layer does not contribute to the message, e.g. a stack
+		// trace or fields: drop it.
+		return newCause
+	case strings.HasSuffix(msg, ": "+causeMsg):
+		return fmt.Errorf("%s: %w", strings.TrimSuffix(msg, ": "+causeMsg), newCause)
+	}
+
+	// The layer replaces the message of its cause, which fmt.Errorf
+	// cannot express: wrap the cause without printing it.
+	return &stdOpaqueWrapper{msg: msg, cause: newCause}
+}
+
+// stdOpaqueWrapper is the result of ToStdError for a layer whose
+// message does not include that of its cause. It deliberately only
+// implements Error and Unwrap, like the wrappers of the standard
+// library; in particular it does not implement fmt.Formatter, so that
+// it does not depend on how its cause formats itself.
+type stdOpaqueWrapper struct {
+	msg   string
+	cause error
+}
+
+func (w *stdOpaqueWrapper) Error() string { return w.msg }
+func (w *stdOpaqueWrapper) Unwrap() error { return w.cause }
+
+// stdJoinError is the result of ToStdError for a multi-error. Like
+// stdOpaqueWrapper, it only implements Error and Unwrap, like the
+// errors.Join() of the standard library, which go.mod does not
+// target.
+type stdJoinError struct {
+	msg  string
+	errs []error
+}
+
+func (e *stdJoinError) Error() string   { return e.msg }
+func (e *stdJoinError) Unwrap() []error { return e.errs }
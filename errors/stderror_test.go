@@ -0,0 +1,65 @@
+package errors
+
+import (
+	stderrors "errors"
+	"io"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestToStdErrorReachesRoot(t *testing.T) {
+	root := stderrors.New("disk full")
+	err := WrapWithFields(Wrap(NotFound(root), "saving"), Fields{"user": "u1"})
+	err = WithHint(err, "free some space")
+
+	std := ToStdError(err)
+	if IsOurs(std) {
+		t.Fatalf("ToStdError() returned one of our types: %#v", std)
+	}
+	if std.Error() != err.Error() {
+		t.Errorf("expected message %q, got %q", err.Error(), std.Error())
+	}
+	var last error
+	for c := std; c != nil; c = stderrors.Unwrap(c) {
+		last = c
+	}
+	if last != root {
+		t.Errorf("expected stderrors.Unwrap() to reach the root %v, got %v", root, last)
+	}
+	if !stderrors.Is(std, root) {
+		t.Errorf("expected stderrors.Is() to find the root")
+	}
+}
+
+func TestToStdErrorFormatterRoot(t *testing.T) {
+	SetIncludeKindInError(true)
+	defer SetIncludeKindInError(false)
+
+	root := pkgerrors.New("x")
+	err := NotFound(root)
+
+	std := ToStdError(err)
+	if std.Error() != err.Error() {
+		t.Errorf("expected message %q, got %q", err.Error(), std.Error())
+	}
+	if !stderrors.Is(std, root) {
+		t.Errorf("expected stderrors.Is() to find the root")
+	}
+}
+
+func TestToStdErrorThroughMultiError(t *testing.T) {
+	root := stderrors.New("other")
+	err := Wrap(multiError{io.EOF, WrapWithFields(NotFound(root), Fields{"user": 42})}, "ctx")
+
+	std := ToStdError(err)
+	if IsOurs(std) {
+		t.Fatalf("ToStdError() returned one of our types: %T", std)
+	}
+	if std.Error() != err.Error() {
+		t.Errorf("expected message %q, got %q", err.Error(), std.Error())
+	}
+	if !stderrors.Is(std, io.EOF) || !stderrors.Is(std, root) {
+		t.Errorf("expected stderrors.Is() to find the roots of both branches")
+	}
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// WithTags annotates err with tags: simple string labels for arbitrary
+// classification, e.g. "user-facing" or "pagerduty". Unlike fields,
+// tags have no value, and unlike kinds, an error can have any number
+// of them: the tags attached at every layer of an error add up (see
+// GetTags). The tags are considered safe for reporting.
+// If err is nil, WithTags returns nil.
+//
+// Detail is shown:
+// - via `errors.GetSafeDetails()`.
+// - when formatting with `%+v`.
+// - in Sentry reports.
+func WithTags(err error, tags ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withTags{cause: err, tags: tags}
+}
+
+// HasTag returns true if tag was attached to err, or any of its
+// causes, with WithTags().
+func HasTag(err error, tag string) bool {
+	return !errbase.Walk(err, func(c error) bool {
+		if w, ok := c.(*withTags); ok {
+			for _, t := range w.tags {
+				if t == tag {
+					return false
+				}
+			}
+		}
+
+		return true
+	})
+}
+
+// GetTags returns the tags attached to err and its causes with
+// WithTags(), from the outermost layer to the innermost, without
+// duplicates.
+func GetTags(err error) []string {
+	var res []string
+	seen := map[string]bool{}
+	errbase.Walk(err, func(c error) bool {
+		if w, ok := c.(*withTags); ok {
+			for _, t := range w.tags {
+				if !seen[t] {
+					seen[t] = true
+					res = append(res, t)
+				}
+			}
+		}
+
+		return true
+	})
+
+	return res
+}
+
+// withTags is an error wrapper that carries tags.
+type withTags struct {
+	cause error
+	tags  []string
+}
+
+var (
+	_ error         = (*withTags)(nil)
+	_ fmt.Formatter = (*withTags)(nil)
+)
+
+func (w *withTags) Error() string { return w.cause.Error() }
+func (w *withTags) Cause() error  { return w.cause }
+func (w *withTags) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *withTags) Format(s fmt.State, verb rune) { errbase.FormatError(w, s, verb) }
+
+// SafeFormatError implements the errbase.SafeFormatter interface.
+func (w *withTags) SafeFormatError(p errbase.Printer) (next error) {
+	if p.Detail() {
+		p.Printf("tags: %s", strings.Join(w.tags, ", "))
+	}
+
+	return w.cause
+}
+
+// SafeDetails implements the errbase.SafeDetailer interface.
+func (w *withTags) SafeDetails() []string {
+	return w.tags
+}
+
+func init() {
+	errbase.RegisterRewrapper((*withTags)(nil), func(err, newCause error) error {
+		return &withTags{cause: newCause, tags: err.(*withTags).tags}
+	})
+}
@@ -0,0 +1,35 @@
+package errors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	err := WithTags(New("boom"), "pagerduty", "internal")
+	err = Wrap(err, "loading")
+	err = WithTags(err, "user-facing", "pagerduty")
+
+	for _, tag := range []string{"user-facing", "pagerduty", "internal"} {
+		if !HasTag(err, tag) {
+			t.Errorf("expected tag %q", tag)
+		}
+	}
+	if HasTag(err, "billing") {
+		t.Error("expected no tag \"billing\"")
+	}
+	if HasTag(New("boom"), "pagerduty") {
+		t.Error("expected no tag on an error without tags")
+	}
+
+	want := []string{"user-facing", "pagerduty", "internal"}
+	if got := GetTags(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := GetTags(New("boom")); got != nil {
+		t.Errorf("expected no tags, got %q", got)
+	}
+	if WithTags(nil, "pagerduty") != nil {
+		t.Error("expected nil")
+	}
+}
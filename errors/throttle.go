@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"sync"
+	"time"
+)
+
+// throttled records, for each throttling key, when its current window
+// started. See NewThrottled().
+var throttled = struct {
+	sync.Mutex
+	window time.Duration
+	starts map[string]time.Time
+	// lastSweep is when the expired windows were last forgotten.
+	lastSweep time.Time
+}{window: time.Minute, starts: map[string]time.Time{}}
+
+// SetThrottleWindow sets the duration during which NewThrottled()
+// reports the repeated occurrences of an error as such. The default
+// is one minute. A duration of 0 disables throttling.
+func SetThrottleWindow(d time.Duration) {
+	throttled.Lock()
+	defer throttled.Unlock()
+	throttled.window = d
+	throttled.starts = map[string]time.Time{}
+}
+
+// NewThrottled returns err and true if this is the first occurrence
+// of the error within the throttle window (see SetThrottleWindow),
+// and err and false otherwise. This lets a subsystem that can fail
+// thousands of times a second with the same error only log or report
+// the first occurrence:
+//
+//	if err, first := errors.NewThrottled("sync", err); first {
+//		log.Printf("%+v", err)
+//	}
+//
+// Occurrences are identified by key, combined with the code of err
+// (see WithCode) if it has one, and otherwise with its MarkKey(). When
+// err carries no source location, e.g. a sentinel error of the
+// standard library such as io.EOF, the message of its root cause is
+// used too, since MarkKey() would be the same for all such errors of
+// the same type. If err is nil, NewThrottled returns nil and false.
+func NewThrottled(key string, err error) (error, bool) {
+	if err == nil {
+		return nil, false
+	}
+	if code, ok := GetCode(err); ok {
+		key += "|code:" + code
+	} else {
+		key += "|" + MarkKey(err)
+		if _, _, _, ok := GetOneLineSource(err); !ok {
+			key += "|" + Cause(err).Error()
+		}
+	}
+	now := time.Now()
+
+	throttled.Lock()
+	defer throttled.Unlock()
+	if start, ok := throttled.starts[key]; ok && now.Sub(start) < throttled.window {
+		return err, false
+	}
+	// Forget the expired windows, so that the map does not grow with
+	// the keys that are not seen anymore. This is done once per window
+	// at most, not to scan the map for every new key.
+	if now.Sub(throttled.lastSweep) >= throttled.window {
+		for k, start := range throttled.starts {
+			if now.Sub(start) >= throttled.window {
+				delete(throttled.starts, k)
+			}
+		}
+		throttled.lastSweep = now
+	}
+	throttled.starts[key] = now
+
+	return err, true
+}
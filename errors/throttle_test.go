@@ -0,0 +1,85 @@
+package errors
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestNewThrottled(t *testing.T) {
+	SetThrottleWindow(time.Hour)
+	defer SetThrottleWindow(time.Minute)
+
+	var firsts []bool
+	for i := 0; i < 5; i++ {
+		_, first := NewThrottled("sync", New("connection reset"))
+		firsts = append(firsts, first)
+	}
+	if firsts[0] != true {
+		t.Errorf("expected the first occurrence to be reported")
+	}
+	for i, first := range firsts[1:] {
+		if first {
+			t.Errorf("occurrence %d: expected a repeated error not to be reported", i+2)
+		}
+	}
+
+	if _, first := NewThrottled("other", New("connection reset")); !first {
+		t.Errorf("expected an error under another key to be reported")
+	}
+	if err, first := NewThrottled("sync", nil); err != nil || first {
+		t.Errorf("expected nil and false for a nil error")
+	}
+}
+
+func TestNewThrottledWindowExpires(t *testing.T) {
+	SetThrottleWindow(time.Millisecond)
+	defer SetThrottleWindow(time.Minute)
+
+	var firsts []bool
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		_, first := NewThrottled("sync", New("connection reset"))
+		firsts = append(firsts, first)
+	}
+	if !firsts[0] || !firsts[1] {
+		t.Errorf("expected the error to be reported again after the window, got %v", firsts)
+	}
+}
+
+func TestNewThrottledSentinels(t *testing.T) {
+	SetThrottleWindow(time.Hour)
+	defer SetThrottleWindow(time.Minute)
+
+	if _, first := NewThrottled("sync", io.EOF); !first {
+		t.Errorf("expected the first io.EOF to be reported")
+	}
+	if _, first := NewThrottled("sync", sql.ErrNoRows); !first {
+		t.Errorf("expected sql.ErrNoRows to be reported despite io.EOF")
+	}
+	if _, first := NewThrottled("sync", io.EOF); first {
+		t.Errorf("expected the repeated io.EOF not to be reported")
+	}
+}
+
+func TestNewThrottledSweepsExpiredWindows(t *testing.T) {
+	SetThrottleWindow(time.Millisecond)
+	defer SetThrottleWindow(time.Minute)
+
+	for i := 0; i < 10; i++ {
+		NewThrottled(fmt.Sprintf("key %d", i), io.EOF)
+	}
+	time.Sleep(5 * time.Millisecond)
+	NewThrottled("last", io.EOF)
+
+	throttled.Lock()
+	n := len(throttled.starts)
+	throttled.Unlock()
+	if n != 1 {
+		t.Errorf("expected the expired windows forgotten, got %d keys", n)
+	}
+}
@@ -0,0 +1,25 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// timeoutField is the field used to mark errors as timeouts.
+const timeoutField = "timeout"
+
+// WithTimeout marks err as being caused by a timeout, and gives it
+// the kind TransientServiceKind so that it is considered for retries
+// (see IsTransient). This is useful for timeouts detected by other
+// means than context.DeadlineExceeded, e.g. by a custom client.
+// If err is nil, WithTimeout returns nil.
+func WithTimeout(err error) error {
+	return khanWrapWithFieldsAndDepth(TransientServiceKind, err, Fields{timeoutField: true}, 1)
+}
+
+// IsTimeoutMarked returns true if err, or any of its causes,
+// was marked with WithTimeout.
+func IsTimeoutMarked(err error) bool {
+	return !errbase.Walk(err, func(c error) bool {
+		marked, ok := layerFields(c)[timeoutField].(bool)
+
+		return !ok || !marked
+	})
+}
@@ -0,0 +1,22 @@
+package errors
+
+import "testing"
+
+func TestWithTimeout(t *testing.T) {
+	err := Wrap(WithTimeout(New("no response from the billing service")), "charging")
+
+	if !IsTimeoutMarked(err) {
+		t.Errorf("expected the error to be marked as a timeout")
+	}
+	// There is no IsRetryable(): IsTransient() is what retry loops
+	// check.
+	if !IsTransient(err) {
+		t.Errorf("expected a timeout to be transient")
+	}
+	if IsTimeoutMarked(TransientService(New("unavailable"))) {
+		t.Errorf("expected an unmarked error not to be a timeout")
+	}
+	if WithTimeout(nil) != nil {
+		t.Errorf("expected nil for a nil error")
+	}
+}
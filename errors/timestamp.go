@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"time"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// timestampField is the field holding the time recorded by
+// WithTimestamp.
+const timestampField = "timestamp"
+
+// WithTimestamp records the current time as a field, in a new layer
+// around err, to correlate where in a pipeline an error occurred for
+// post-hoc analysis. If err is nil, WithTimestamp returns nil.
+func WithTimestamp(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Round(0) strips the monotonic clock reading, which is
+	// meaningless outside of this process and clutters the output.
+	return WrapWithFieldsAndDepth(err, Fields{timestampField: time.Now().Round(0)}, 1)
+}
+
+// GetTimestamp returns the time recorded by WithTimestamp on err, or
+// on any of its causes. When there are several, the innermost, i.e.
+// the earliest, is returned: the time the error first occurred. The
+// timestamp is also found after a round trip through ToJSON and
+// FromJSON, which encode it as an RFC 3339 string.
+func GetTimestamp(err error) (time.Time, bool) {
+	var res time.Time
+	found := false
+	errbase.Walk(err, func(c error) bool {
+		if t, ok := timestampFieldValue(layerFields(c)[timestampField]); ok {
+			res, found = t, true
+		}
+
+		return true
+	})
+
+	return res, found
+}
+
+// timestampFieldValue returns v as a time, whether it is still a
+// time.Time or the RFC 3339 string it is encoded to in JSON.
+func timestampFieldValue(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+
+		return parsed, err == nil
+	}
+
+	return time.Time{}, false
+}
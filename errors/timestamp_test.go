@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetTimestamp(t *testing.T) {
+	if _, ok := GetTimestamp(New("boom")); ok {
+		t.Error("expected no timestamp")
+	}
+
+	before := time.Now()
+	inner := WithTimestamp(New("boom"))
+	after := time.Now()
+	first, ok := GetTimestamp(inner)
+	if !ok {
+		t.Fatal("expected a timestamp")
+	}
+	if first.Before(before.Round(0)) || first.After(after) {
+		t.Errorf("expected a timestamp between %v and %v, got %v", before, after, first)
+	}
+
+	time.Sleep(time.Millisecond)
+	err := WithTimestamp(Wrap(inner, "loading"))
+	if got, _ := GetTimestamp(err); !got.Equal(first) {
+		t.Errorf("expected the earliest timestamp %v, got %v", first, got)
+	}
+	outer, _ := GetField(err, timestampField)
+	if !outer.(time.Time).After(first) {
+		t.Errorf("expected the outer timestamp %v after %v", outer, first)
+	}
+
+	if WithTimestamp(nil) != nil {
+		t.Error("expected nil")
+	}
+}
+
+func TestGetTimestampAfterJSON(t *testing.T) {
+	err := WithTimestamp(New("boom"))
+	want, _ := GetTimestamp(err)
+	data, encErr := ToJSON(err)
+	if encErr != nil {
+		t.Fatal(encErr)
+	}
+	decoded, decErr := FromJSON(data)
+	if decErr != nil {
+		t.Fatal(decErr)
+	}
+	got, ok := GetTimestamp(decoded)
+	if !ok {
+		t.Fatal("expected a timestamp after decoding")
+	}
+	if !got.Equal(want) {
+		t.Errorf("expected %v after decoding, got %v", want, got)
+	}
+}
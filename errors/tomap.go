@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// ToMap converts err to a map suitable for the structured loggers
+// that accept a map[string]interface{}, with the keys:
+// - "message": the message of err.
+// - "kind": the kind of err (see GetKind).
+// - "fields": the fields of err, merged across its layers (see
+// GetFields), if any.
+// - "source": the location where err was created, as "file:line",
+// if known (see GetOneLineSource).
+// - "stack": the innermost stack trace of err, if any, as a list of
+// "function file:line" frames, the innermost call first.
+//
+// Returns nil if err is nil.
+func ToMap(err error) map[string]interface{} {
+	if err == nil {
+		return nil
+	}
+	m := map[string]interface{}{
+		"message": err.Error(),
+		"kind":    GetKind(err).String(),
+	}
+	if fields := GetFields(err); len(fields) != 0 {
+		m["fields"] = fields
+	}
+	if file, line, _, ok := GetOneLineSource(err); ok {
+		m["source"] = fmt.Sprintf("%s:%d", file, line)
+	}
+	if st := innermostStackTrace(err); len(st) != 0 {
+		frames := make([]string, len(st))
+		for i, f := range st {
+			text, _ := f.MarshalText()
+			frames[i] = string(text)
+		}
+		m["stack"] = frames
+	}
+
+	return m
+}
+
+// innermostStackTrace returns the stack trace of the innermost layer
+// of err that has one, or nil.
+func innermostStackTrace(err error) errbase.StackTrace {
+	var res errbase.StackTrace
+	errbase.Walk(err, func(c error) bool {
+		if p, ok := c.(errbase.StackTraceProvider); ok {
+			if st := p.StackTrace(); len(st) != 0 {
+				res = st
+			}
+		}
+
+		return true
+	})
+
+	return res
+}
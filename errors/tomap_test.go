@@ -0,0 +1,47 @@
+package errors
+
+import (
+	stderrors "errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestToMap(t *testing.T) {
+	err, l := Wrap(WrapWithFields(NotFound("no user"), Fields{"user": 42}), "loading"), line()
+
+	m := ToMap(err)
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if want := []string{"fields", "kind", "message", "source", "stack"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("expected the keys %q, got %q", want, keys)
+	}
+
+	if got := m["message"]; got != "loading: not found" {
+		t.Errorf("expected message %q, got %v", "loading: not found", got)
+	}
+	if got := m["kind"]; got != NotFoundKind.String() {
+		t.Errorf("expected kind %q, got %v", NotFoundKind, got)
+	}
+	if want := (Fields{"user": 42, "message": "no user"}); !reflect.DeepEqual(m["fields"], want) {
+		t.Errorf("expected fields %v, got %v", want, m["fields"])
+	}
+	if got := m["source"].(string); !strings.HasSuffix(got, "tomap_test.go:"+strconv.Itoa(l)) {
+		t.Errorf("expected the source in the test, got %q", got)
+	}
+	if frames := m["stack"].([]string); !strings.Contains(frames[0], "TestToMap") {
+		t.Errorf("expected the test as top frame, got %q", frames)
+	}
+
+	if m := ToMap(stderrors.New("boom")); len(m) != 2 {
+		t.Errorf("expected only the message and kind, got %v", m)
+	}
+	if ToMap(nil) != nil {
+		t.Error("expected nil")
+	}
+}
@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+	"github.com/StevenACoffman/anotherr/errors/hintdetail"
+)
+
+// kindUserMessages holds the message shown to end users for errors of
+// each kind, when the error has no hint. See UserMessage().
+var kindUserMessages = struct {
+	errbase.Registry
+	msgs map[errorKind]string
+}{msgs: map[errorKind]string{
+	NotFoundKind:             "The requested resource was not found.",
+	InvalidInputKind:         "The request was invalid.",
+	NotAllowedKind:           "The requested operation is not allowed.",
+	UnauthorizedKind:         "You are not authorized to perform this operation.",
+	InternalKind:             "An internal error occurred.",
+	NotImplementedKind:       "This operation is not implemented.",
+	GraphqlResponseKind:      "An error occurred while processing the request.",
+	TransientKhanServiceKind: "A temporary error occurred. Please try again.",
+	KhanServiceKind:          "An error occurred while processing the request.",
+	TransientServiceKind:     "A temporary error occurred. Please try again.",
+	ServiceKind:              "An error occurred while processing the request.",
+	UnspecifiedKind:          "An unexpected error occurred.",
+}}
+
+// RegisterKindUserMessage customizes the message returned by
+// UserMessage() for errors of the given kind that have no hint.
+// This should be called at init time, but is safe to call
+// concurrently with UserMessage().
+func RegisterKindUserMessage(kind ErrorKind, msg string) {
+	kindUserMessages.Update(func() { kindUserMessages.msgs[kind] = msg })
+}
+
+// getKindUserMessage returns the message registered for kind, falling
+// back to the one of UnspecifiedKind.
+func getKindUserMessage(kind errorKind) (msg string) {
+	kindUserMessages.Read(func() {
+		var ok bool
+		if msg, ok = kindUserMessages.msgs[kind]; !ok {
+			msg = kindUserMessages.msgs[UnspecifiedKind]
+		}
+	})
+
+	return msg
+}
+
+// UserMessage returns a message suitable to show to the end user for
+// err: the outermost hint attached with WithHint(), if any, otherwise
+// a generic message for the kind of err (see EffectiveKind). Since the
+// message of err itself is never used, no internal detail or PII is
+// exposed (unless put in a hint). Returns "" if err is nil.
+func UserMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	var hinter hintdetail.ErrorHinter
+	errbase.Walk(err, func(c error) bool {
+		h, ok := c.(hintdetail.ErrorHinter)
+		if ok {
+			hinter = h
+		}
+
+		return !ok
+	})
+	if hinter != nil {
+		return hinter.ErrorHint()
+	}
+
+	return getKindUserMessage(EffectiveKind(err))
+}
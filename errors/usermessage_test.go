@@ -0,0 +1,27 @@
+package errors
+
+import "testing"
+
+func TestUserMessage(t *testing.T) {
+	err := WithHint(Wrap(WithHint(NotFound("no user 42"), "inner hint"), "loading"), "Check the user ID.")
+	if got := UserMessage(err); got != "Check the user ID." {
+		t.Errorf("expected the outermost hint, got %q", got)
+	}
+
+	err = Wrap(NotFound("no user 42"), "loading")
+	if got, want := UserMessage(err), "The requested resource was not found."; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got, want := UserMessage(New("secret detail")), "An unexpected error occurred."; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRegisterKindUserMessage(t *testing.T) {
+	defer RegisterKindUserMessage(NotImplementedKind, getKindUserMessage(NotImplementedKind))
+	RegisterKindUserMessage(NotImplementedKind, "Coming soon.")
+
+	if got := UserMessage(NotImplemented("bulk edit")); got != "Coming soon." {
+		t.Errorf("expected the registered message, got %q", got)
+	}
+}
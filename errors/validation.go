@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// validationErrorsField is the field holding the per-field messages
+// of a validation error.
+const validationErrorsField = "validation_errors"
+
+// NewValidationError creates an error of kind InvalidInputKind for a
+// failed validation, e.g. of a form. fieldErrors maps the name of each
+// invalid field to a message describing the problem with it; they can
+// be retrieved with GetValidationErrors(). The message of the
+// error only summarizes the number of invalid fields.
+func NewValidationError(fieldErrors map[string]string) error {
+	msgs := make(map[string]string, len(fieldErrors))
+	for k, v := range fieldErrors {
+		msgs[k] = v
+	}
+
+	noun := "fields"
+	if len(msgs) == 1 {
+		noun = "field"
+	}
+	cause := fmt.Errorf("%d %s failed validation", len(msgs), noun)
+
+	return khanWrapWithFieldsAndDepth(
+		InvalidInputKind, cause, Fields{validationErrorsField: msgs}, 1)
+}
+
+// GetValidationErrors retrieves the per-field messages of the first
+// validation error in the chain of err, or nil if there is none.
+// See NewValidationError().
+func GetValidationErrors(err error) map[string]string {
+	var res map[string]string
+	errbase.Walk(err, func(c error) bool {
+		switch msgs := layerFields(c)[validationErrorsField].(type) {
+		case map[string]string:
+			res = msgs
+
+			return false
+		case map[string]interface{}:
+			// The error went through FromJSON().
+			res = make(map[string]string, len(msgs))
+			for k, v := range msgs {
+				res[k] = fmt.Sprint(v)
+			}
+
+			return false
+		}
+
+		return true
+	})
+
+	return res
+}
@@ -0,0 +1,28 @@
+package errors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidationError(t *testing.T) {
+	fieldErrors := map[string]string{
+		"email": "is not an email address",
+		"name":  "is required",
+		"age":   "must be positive",
+	}
+	err := Wrap(NewValidationError(fieldErrors), "signing up")
+
+	if got, want := err.Error(), "signing up: 3 fields failed validation"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if kind := GetKind(err); kind != InvalidInputKind {
+		t.Errorf("expected kind %v, got %v", InvalidInputKind, kind)
+	}
+	if got := GetValidationErrors(err); !reflect.DeepEqual(got, fieldErrors) {
+		t.Errorf("expected %v, got %v", fieldErrors, got)
+	}
+	if got := GetValidationErrors(New("x")); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
@@ -0,0 +1,33 @@
+package errors
+
+import "github.com/StevenACoffman/anotherr/errors/errbase"
+
+// WalkDetailed calls fn for each layer in the chain of err, from the
+// outermost to the innermost, with all the metadata of the layer at
+// once: its kind (UnspecifiedKind if the layer is not a Khan error or
+// an errorKind), its own fields (see GetAllFields) and its stack trace
+// (nil if the layer has none). The walk stops when fn returns false.
+//
+// This lets reporters collect everything about an error in a single
+// pass, instead of calling GetKind(), GetAllFields(), etc, which each
+// walk the chain.
+func WalkDetailed(
+	err error,
+	fn func(layer error, kind ErrorKind, fields Fields, stack errbase.StackTrace) bool,
+) {
+	errbase.Walk(err, func(c error) bool {
+		kind := UnspecifiedKind
+		switch e := c.(type) {
+		case *khanError:
+			kind = e.kind
+		case errorKind:
+			kind = e
+		}
+		var st errbase.StackTrace
+		if p, ok := c.(errbase.StackTraceProvider); ok {
+			st = p.StackTrace()
+		}
+
+		return fn(c, kind, visibleFields(c), st)
+	})
+}
@@ -0,0 +1,56 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+func TestWalkDetailed(t *testing.T) {
+	root, rootLine := NotFound("no user"), line()
+	err, errLine := WrapWithFields(root, Fields{"user": 42}), line()
+
+	type layer struct {
+		kind   ErrorKind
+		fields Fields
+		line   int // 0 if the layer has no stack trace
+	}
+	want := []layer{
+		{UnspecifiedKind, Fields{"user": 42}, errLine},
+		{NotFoundKind, Fields{"message": "no user"}, rootLine},
+		{NotFoundKind, nil, 0},
+	}
+	var got []layer
+	WalkDetailed(err, func(_ error, kind ErrorKind, fields Fields, stack errbase.StackTrace) bool {
+		l := layer{kind: kind, fields: fields}
+		if len(stack) != 0 {
+			top := fmt.Sprintf("%+v", stack[0])
+			if !strings.Contains(top, "walk_test.go:") {
+				t.Errorf("expected the top frame in the test, got %s", top)
+			}
+			fmt.Sscanf(top[strings.LastIndexByte(top, ':')+1:], "%d", &l.line)
+		}
+		if len(l.fields) == 0 {
+			l.fields = nil
+		}
+		got = append(got, l)
+
+		return true
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	var n int
+	WalkDetailed(err, func(error, ErrorKind, Fields, errbase.StackTrace) bool {
+		n++
+
+		return false
+	})
+	if n != 1 {
+		t.Errorf("expected the walk to stop after 1 layer, got %d", n)
+	}
+}
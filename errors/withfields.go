@@ -2,12 +2,19 @@ package errors
 
 import (
 	"fmt"
+	"math"
+	"reflect"
 	"sort"
 
 	"github.com/StevenACoffman/anotherr/errors/errbase"
 )
 
 // WithFields is our wrapper type.
+//
+// There is deliberately no Is() method: Is() and As(), ours and those
+// of the standard library, see through the fields via Unwrap(), so a
+// sentinel wrapped with WrapWithFields() or KhanWrap() still matches.
+// The embedded *stack does not take part in the comparisons.
 type withFields struct {
 	cause  error
 	fields Fields
@@ -28,20 +35,314 @@ func WrapWithFields(err error, fields Fields) error {
 // WrapWithFieldsAndDepth adds fields to an existing error
 // and captures the stacktrace
 func WrapWithFieldsAndDepth(err error, fields Fields, depth int) error {
-	if err == nil {
+	if err == nil || errbase.IsTypedNil(err) {
 		return nil
 	}
 
 	return &withFields{cause: err, fields: fields, stack: callers(depth + 1)}
 }
 
-// GetFields retrieves the Fields from a stack of causes.
+// WrapWithFieldsMerged is like WrapWithFields, except that if err is
+// itself a withFields wrapper, the fields are merged into a copy of it
+// instead of adding a layer, to keep the chains of field-heavy code
+// short. As with GetFields(), the new fields override those of err on
+// conflicts. The stack trace of err is kept.
+func WrapWithFieldsMerged(err error, fields Fields) error {
+	w, ok := err.(*withFields)
+	if !ok {
+		return WrapWithFieldsAndDepth(err, fields, 1)
+	}
+	merged := make(Fields, len(w.fields)+len(fields))
+	for k, v := range w.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &withFields{cause: w.cause, fields: merged, stack: w.stack}
+}
+
+// WrapEachWithFields adds fields to each error in errs, like
+// WrapWithFields. The nil entries stay nil. The result is a new slice;
+// its errors share the fields map, which must not be modified
+// afterwards.
+func WrapEachWithFields(errs []error, fields Fields) []error {
+	if errs == nil {
+		return nil
+	}
+	res := make([]error, len(errs))
+	for i, err := range errs {
+		res[i] = WrapWithFieldsAndDepth(err, fields, 1)
+	}
+
+	return res
+}
+
+// WithCaller annotates err with the location of the function calling
+// WithCaller, as a "source" field. This is much cheaper than
+// WithStack() when only the location of the failure matters, and
+// GetOneLineSource() recognizes it like a stack trace.
+func WithCaller(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st := caller(1)
+	fields := Fields{}
+	if len(*st) > 0 {
+		fields["source"] = errbase.StackFrame((*st)[0])
+	}
+
+	return &withFields{cause: err, fields: fields, stack: st}
+}
+
+// GetFields retrieves the Fields from a stack of causes, merged into
+// a single map. When a key is set at several layers, the outermost
+// value, i.e. the one added most recently, overrides the inner ones,
+// so that a caller can refine the fields of the errors it receives.
+// Returns nil if there are no fields. The result is a new map that
+// the caller may modify.
 func GetFields(err error) Fields {
-	if w, ok := err.(*withFields); ok {
-		return w.fields
+	return mergedFields(err)
+}
+
+// GetField returns the value of the field key in the chain of err.
+// If the key is set at several layers, the outermost value wins.
+// This is cheaper than merging all the fields when only one is
+// needed, e.g. "request_id".
+func GetField(err error, key string) (interface{}, bool) {
+	var res interface{}
+	found := false
+	errbase.Walk(err, func(c error) bool {
+		res, found = layerFields(c)[key]
+
+		return !found
+	})
+
+	return res, found
+}
+
+// FieldsToKVs flattens the fields of all the layers of err into
+// alternating keys and values, sorted by key, e.g.
+// ["key1", val1, "key2", val2]. When a key is set at several layers,
+// the outermost value wins. This is the form expected by structured
+// loggers such as logr or zap's SugaredLogger:
+//
+//	logger.Errorw(err.Error(), errors.FieldsToKVs(err)...)
+func FieldsToKVs(err error) []interface{} {
+	fields := mergedFields(err)
+	if len(fields) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, k, fields[k])
+	}
+
+	return kvs
+}
+
+// mergedFields returns the fields of all the layers of err, merged
+// into a single map. On conflicts, the outermost value wins.
+func mergedFields(err error) Fields {
+	var res Fields
+	errbase.Walk(err, func(c error) bool {
+		for k, v := range layerFields(c) {
+			if res == nil {
+				res = Fields{}
+			}
+			if _, ok := res[k]; !ok {
+				res[k] = v
+			}
+		}
+
+		return true
+	})
+
+	return res
+}
+
+// GetAllFields returns the fields attached to each layer of err,
+// from the outermost to the innermost. The layers without fields are
+// skipped. See SetDeduplicateFields() to omit the fields repeated
+// across layers.
+func GetAllFields(err error) []Fields {
+	var res []Fields
+	errbase.Walk(err, func(c error) bool {
+		if fields := visibleFields(c); len(fields) != 0 {
+			res = append(res, fields)
+		}
+
+		return true
+	})
+
+	return res
+}
+
+// deduplicateFields, when set, hides the fields of a layer that are
+// repeated, with an equal value, in one of its causes.
+var deduplicateFields = struct {
+	errbase.Registry
+	enabled bool
+}{}
+
+// SetDeduplicateFields controls whether a key/value pair attached to
+// several layers of an error, e.g. a request ID added at every level,
+// is shown once or at every layer by GetAllFields() and when
+// formatting with %+v. When enabled, only the innermost occurrence is
+// shown. Pairs are only collapsed when their values are equal, as per
+// reflect.DeepEqual; a key with different values at different layers
+// is still shown at each of them. Disabled by default.
+func SetDeduplicateFields(enabled bool) {
+	deduplicateFields.Update(func() { deduplicateFields.enabled = enabled })
+}
+
+func shouldDeduplicateFields() (enabled bool) {
+	deduplicateFields.Read(func() { enabled = deduplicateFields.enabled })
+
+	return enabled
+}
+
+// maxFormattedFields caps the number of fields printed per layer. See
+// SetMaxFormattedFields().
+var maxFormattedFields = struct {
+	errbase.Registry
+	n int
+}{}
+
+// SetMaxFormattedFields limits the number of fields of each layer
+// that are printed when formatting an error, e.g. with %+v, to keep a
+// layer with thousands of fields from flooding the logs. The first n
+// fields, by key, are printed, followed by "... (M more fields)". The
+// fields remain available in full with GetFields(). A value of 0, the
+// default, removes the limit.
+func SetMaxFormattedFields(n int) {
+	maxFormattedFields.Update(func() { maxFormattedFields.n = n })
+}
+
+func getMaxFormattedFields() (n int) {
+	maxFormattedFields.Read(func() { n = maxFormattedFields.n })
+
+	return n
+}
+
+// visibleFields returns the fields attached directly to err that
+// should be shown, taking SetDeduplicateFields() into account.
+func visibleFields(err error) Fields {
+	fields := layerFields(err)
+	if len(fields) == 0 || !shouldDeduplicateFields() {
+		return fields
+	}
+	res := Fields{}
+	for k, v := range fields {
+		if !repeatedInCauses(err, k, v) {
+			res[k] = v
+		}
 	}
 
-	return nil
+	return res
+}
+
+// repeatedInCauses returns true if one of the causes of err has a
+// field k with a value equal to v.
+func repeatedInCauses(err error, k string, v interface{}) bool {
+	return !errbase.Walk(errbase.UnwrapOnce(err), func(c error) bool {
+		cv, ok := layerFields(c)[k]
+
+		return !ok || !reflect.DeepEqual(cv, v)
+	})
+}
+
+// StripFields returns a copy of err with all the fields removed, for
+// when the error is about to be exposed outside of our systems. The
+// messages, kinds and stack traces in the chain are preserved, as is
+// the "message" field of Khan errors, since it is part of their shape.
+// The fields in the branches of multi-errors are removed too: a
+// multi-error with fields in its branches is replaced by an equivalent
+// one (see errbase.NewOpaqueMulti).
+func StripFields(err error) error {
+	if err == nil {
+		return nil
+	}
+	if m, ok := err.(interface{ Unwrap() []error }); ok {
+		branches := m.Unwrap()
+		newBranches := make([]error, len(branches))
+		changed := false
+		for i, b := range branches {
+			newBranches[i] = StripFields(b)
+			changed = changed || newBranches[i] != b
+		}
+		if !changed {
+			return err
+		}
+
+		return errbase.NewOpaqueMulti(newBranches, errbase.TypeName(err))
+	}
+	cause := errbase.UnwrapOnce(err)
+	if cause == nil {
+		return err
+	}
+	newCause := StripFields(cause)
+
+	switch w := err.(type) {
+	case *withFields:
+		return &withFields{cause: newCause, stack: w.stack}
+	case *khanError:
+		var fields Fields
+		if msg, ok := w.fields["message"]; ok {
+			fields = Fields{"message": msg}
+		}
+
+		return &khanError{kind: w.kind, cause: newCause, fields: fields, stack: w.stack}
+	case *withSafeFields:
+		return newCause
+	}
+	if newCause == cause {
+		return err
+	}
+
+	return errbase.Rewrap(err, newCause)
+}
+
+// layerFields returns the fields attached directly to err, not to its
+// causes, if err is a Khan error or a withFields or withSafeFields
+// wrapper. The structure of the errors of the os package, e.g. the
+// path of an *os.PathError, is also exposed as fields.
+func layerFields(err error) Fields {
+	switch e := err.(type) {
+	case *withFields:
+		return e.fields
+	case *khanError:
+		return e.fields
+	case *withSafeFields:
+		return e.fields
+	}
+
+	return osErrorFields(err)
+}
+
+// intFieldValue returns v as an int, for the fields that hold one,
+// e.g. the retry count. Besides an int, it accepts an int64, and the
+// integral float64 that FromJSON decodes numbers to.
+func intFieldValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		if n == math.Trunc(n) {
+			return int(n), true
+		}
+	}
+
+	return 0, false
 }
 
 // it's an error.
@@ -59,34 +360,13 @@ func (w *withFields) Format(s fmt.State, verb rune) { errbase.FormatError(w, s,
 // on how to implement this. In particular beware of not emitting
 // unsafe strings.
 func (w *withFields) SafeFormatError(p errbase.Printer) (next error) {
-	if p.Detail() && w.fields != nil && len(w.fields) != 0 {
-		var empty string
-		p.Printf("fields: [")
-
-		keys := make([]string, 0, len(w.fields))
-		for k := range w.fields {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-		for i, k := range keys {
-			v := w.fields[k]
-			eq := empty
-			var val interface{} = empty
-			fmt.Println(k, w.fields[k])
-			if i > 0 {
-				p.Printf(",")
-			}
-			if v != nil {
-				if len(k) > 1 {
-					eq = ":"
-				}
-				val = v
-			}
-
-			p.Print(fmt.Sprintf("%s%s%v", k, eq, val))
+	if errbase.WantsInlineDetail(p) {
+		printInlineFields(p, visibleFields(w))
+	}
+	if p.Detail() {
+		if fields := visibleFields(w); len(fields) != 0 {
+			printFields(p, fields)
 		}
-
-		p.Printf("], ")
 	}
 
 	// We do not print the stack trace ourselves - errbase.FormatError()
@@ -94,21 +374,68 @@ func (w *withFields) SafeFormatError(p errbase.Printer) (next error) {
 	return w.cause
 }
 
+// printFields prints fields as "fields: [k1:v1, k2:v2]", sorted by key.
+func printFields(p errbase.Printer, fields Fields) {
+	p.Printf("fields: [")
+	fieldsIterate(fields, func(i int, r string) {
+		if i > 0 {
+			p.Printf(", ")
+		}
+		p.Print(r)
+	})
+	p.Printf("]")
+}
+
+// printInlineFields prints fields as "[k1:v1, k2:v2]", sorted by key,
+// for the single-line output of `% v`. Nothing is printed if there are
+// no fields.
+func printInlineFields(p errbase.Printer, fields Fields) {
+	if len(fields) == 0 {
+		return
+	}
+	p.Print("[")
+	fieldsIterate(fields, func(i int, r string) {
+		if i > 0 {
+			p.Print(", ")
+		}
+		p.Print(r)
+	})
+	p.Print("]")
+}
+
+// fieldsIterate calls fn with the rendering of each of the fields,
+// in the order of their keys. The values matching the patterns
+// registered with RegisterRedactionPattern() are masked. Past the
+// limit set with SetMaxFormattedFields(), the remaining fields are
+// summarized by a last call to fn.
 func fieldsIterate(fields Fields, fn func(i int, s string)) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	more := 0
+	if n := getMaxFormattedFields(); n > 0 && len(keys) > n {
+		more = len(keys) - n
+		keys = keys[:n]
+	}
+
 	var empty string
-	i := 0
-	for k, v := range fields {
+	for i, k := range keys {
+		v := fields[k]
 		eq := empty
 		var val interface{} = empty
 		if v != nil {
 			if len(k) > 1 {
 				eq = ":"
 			}
-			val = v
+			val = redactFieldValue(v)
 		}
 		res := fmt.Sprintf("%s%s%v", k, eq, val)
 		fn(i, res)
-		i++
+	}
+	if more > 0 {
+		fn(len(keys), fmt.Sprintf("... (%d more fields)", more))
 	}
 }
 
@@ -117,6 +444,14 @@ func (w *withFields) SafeDetails() []string {
 	return []string{fmt.Sprintf("%+v", w.StackTrace())}
 }
 
+func init() {
+	errbase.RegisterRewrapper((*withFields)(nil), func(err, newCause error) error {
+		w := err.(*withFields)
+
+		return &withFields{cause: newCause, fields: w.fields, stack: w.stack}
+	})
+}
+
 //func (w *withFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 //	enc.AddString("message", w.Error())
 //	enc.AddString("stacktrace", fmt.Sprintf("%+v", w.StackTrace()))
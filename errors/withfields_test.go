@@ -0,0 +1,238 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsSeesThroughFields(t *testing.T) {
+	sentinel := stderrors.New("sentinel")
+
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{"WrapWithFields", WrapWithFields(sentinel, Fields{"user": 42})},
+		{"KhanWrap", KhanWrap(sentinel, "loading", Fields{"user": 42})},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if !Is(tc.err, sentinel) {
+				t.Errorf("expected Is() to find the sentinel")
+			}
+			if !stderrors.Is(tc.err, sentinel) {
+				t.Errorf("expected the standard errors.Is() to find the sentinel")
+			}
+		})
+	}
+}
+
+func TestStripFields(t *testing.T) {
+	err := Wrap(WrapWithFields(Internal(New("boom"), Fields{"query": "q"}), Fields{"user": 42}), "saving")
+	stripped := StripFields(err)
+
+	if fields := GetFields(stripped); fields != nil {
+		t.Errorf("expected no fields, got %v", fields)
+	}
+	if got, want := stripped.Error(), err.Error(); got != want {
+		t.Errorf("expected Error() to be unchanged: %q, got %q", want, got)
+	}
+	if kind := GetKind(stripped); kind != InternalKind {
+		t.Errorf("expected kind %v, got %v", InternalKind, kind)
+	}
+	if fields := GetFields(err); len(fields) != 2 {
+		t.Errorf("expected the original error to keep its fields, got %v", fields)
+	}
+}
+
+func TestStripFieldsThroughMultiError(t *testing.T) {
+	sentinel := stderrors.New("b")
+	err := Wrap(multiError{WrapWithFields(New("a"), Fields{"secret": "pw"}), sentinel}, "ctx")
+	stripped := StripFields(err)
+
+	if fields := GetFields(stripped); fields != nil {
+		t.Errorf("expected no fields, got %v", fields)
+	}
+	if verbose := fmt.Sprintf("%+v", stripped); strings.Contains(verbose, "pw") {
+		t.Errorf("expected no fields in %%+v, got:\n%s", verbose)
+	}
+	if got, want := stripped.Error(), err.Error(); got != want {
+		t.Errorf("expected Error() to be unchanged: %q, got %q", want, got)
+	}
+	if !Is(stripped, sentinel) {
+		t.Error("expected the branches to be preserved")
+	}
+
+	plain := multiError{New("a"), sentinel}
+	if got := StripFields(plain); !reflect.DeepEqual(got, plain) {
+		t.Errorf("expected a multi-error without fields unchanged, got %T", got)
+	}
+}
+
+func TestWithCaller(t *testing.T) {
+	err, callerLine := WithCaller(stderrors.New("boom")), line()
+
+	file, l, fn, ok := GetOneLineSource(err)
+	if !ok {
+		t.Fatal("no source found")
+	}
+	if !strings.HasSuffix(file, "withfields_test.go") || l != callerLine || fn != "TestWithCaller" {
+		t.Errorf("expected TestWithCaller at withfields_test.go:%d, got %s at %s:%d", callerLine, fn, file, l)
+	}
+	if _, ok := GetField(err, "source"); !ok {
+		t.Errorf("expected a source field")
+	}
+}
+
+// atDepth calls fn from n frames deeper in the stack, as errors are
+// usually created far from main().
+func atDepth(n int, fn func()) {
+	if n == 0 {
+		fn()
+
+		return
+	}
+	atDepth(n-1, fn)
+}
+
+func BenchmarkWithCaller(b *testing.B) {
+	root := stderrors.New("boom")
+	b.Run("WithCaller", func(b *testing.B) {
+		atDepth(30, func() {
+			for i := 0; i < b.N; i++ {
+				_ = WithCaller(root)
+			}
+		})
+	})
+	b.Run("WithStack", func(b *testing.B) {
+		atDepth(30, func() {
+			for i := 0; i < b.N; i++ {
+				_ = WithStack(root)
+			}
+		})
+	})
+}
+
+func TestDeduplicateFields(t *testing.T) {
+	err := WrapWithFields(Wrap(WrapWithFields(WrapWithFields(New("x"),
+		Fields{"id": 5}), Fields{"id": 5, "user": 42}), "loading"), Fields{"id": 5})
+
+	if n := strings.Count(fmt.Sprintf("%+v", err), "id:5"); n != 3 {
+		t.Errorf("expected the field at every layer by default, found it %d times", n)
+	}
+
+	SetDeduplicateFields(true)
+	defer SetDeduplicateFields(false)
+	verbose := fmt.Sprintf("%+v", err)
+	if n := strings.Count(verbose, "id:5"); n != 1 {
+		t.Errorf("expected the field once, found it %d times in:\n%s", n, verbose)
+	}
+	if !strings.Contains(verbose, "user:42") {
+		t.Errorf("expected the other fields to remain, got:\n%s", verbose)
+	}
+	n := 0
+	for _, fields := range GetAllFields(err) {
+		if _, ok := fields["id"]; ok {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected the field once in GetAllFields, found it %d times", n)
+	}
+}
+
+func TestFieldsToKVs(t *testing.T) {
+	err := WrapWithFields(Wrap(NotFound("no user", Fields{"user": 42, "request_id": "r1"}), "loading"),
+		Fields{"request_id": "r2", "attempt": 3})
+
+	kvs := FieldsToKVs(err)
+	want := []interface{}{"attempt", 3, "message", "no user", "request_id", "r2", "user", 42}
+	if !reflect.DeepEqual(kvs, want) {
+		t.Errorf("expected %v, got %v", want, kvs)
+	}
+	merged := Fields{}
+	for i := 0; i < len(kvs); i += 2 {
+		merged[kvs[i].(string)] = kvs[i+1]
+	}
+	if fields := GetFields(err); !reflect.DeepEqual(merged, fields) {
+		t.Errorf("expected the KVs to match the merged fields %v, got %v", fields, merged)
+	}
+	if kvs := FieldsToKVs(New("x")); kvs != nil {
+		t.Errorf("expected nil, got %v", kvs)
+	}
+}
+
+func TestGetField(t *testing.T) {
+	err := Wrap(WrapWithFields(WrapWithFields(New("boom"), Fields{"request_id": "r1"}), Fields{"user": 42}), "loading")
+
+	if v, ok := GetField(err, "request_id"); !ok || v != "r1" {
+		t.Errorf("expected the inner field, got %v, %v", v, ok)
+	}
+	if v, ok := GetField(err, "email"); ok {
+		t.Errorf("expected no field, got %v", v)
+	}
+}
+
+func TestGetFieldsOuterWins(t *testing.T) {
+	err := WrapWithFields(New("boom"), Fields{"step": "inner", "inner": 1})
+	err = WrapWithFields(Wrap(err, "loading"), Fields{"step": "middle", "middle": 2})
+	err = WrapWithFields(err, Fields{"step": "outer", "outer": 3})
+
+	want := Fields{"step": "outer", "inner": 1, "middle": 2, "outer": 3}
+	got := GetFields(err)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// The result is a copy.
+	got["step"] = "changed"
+	if step, _ := GetField(err, "step"); step != "outer" {
+		t.Errorf("expected the fields of the error unchanged, got %v", step)
+	}
+	if got := GetFields(New("boom")); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
+
+func TestWrapWithFieldsMerged(t *testing.T) {
+	root := stderrors.New("boom")
+	first := WrapWithFieldsMerged(root, Fields{"user": 42, "step": "load"})
+	err := WrapWithFieldsMerged(first, Fields{"step": "save", "shard": 3})
+
+	want := []Fields{{"user": 42, "step": "save", "shard": 3}}
+	if got := GetAllFields(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected a single layer with %v, got %v", want, got)
+	}
+	if Unwrap(err) != root {
+		t.Errorf("expected the merged layer directly around the root, got %v", Unwrap(err))
+	}
+	if StackCount(err) != 1 {
+		t.Errorf("expected the stack trace kept, got %d", StackCount(err))
+	}
+	if want := (Fields{"user": 42, "step": "load"}); !reflect.DeepEqual(GetFields(first), want) {
+		t.Errorf("expected the first error unchanged, got %v", GetFields(first))
+	}
+}
+
+func TestSetMaxFormattedFields(t *testing.T) {
+	fields := Fields{}
+	for i := 0; i < 1000; i++ {
+		fields[fmt.Sprintf("key%04d", i)] = i
+	}
+	err := WrapWithFields(stderrors.New("boom"), fields)
+
+	SetMaxFormattedFields(10)
+	defer SetMaxFormattedFields(0)
+	verbose := fmt.Sprintf("%+v", err)
+	if !strings.Contains(verbose, "... (990 more fields)") {
+		t.Errorf("expected the truncation marker, got:\n%s", verbose)
+	}
+	if !strings.Contains(verbose, "key0009:9") || strings.Contains(verbose, "key0010") {
+		t.Errorf("expected only the first 10 keys, got:\n%s", verbose)
+	}
+	if got := GetFields(err); len(got) != 1000 {
+		t.Errorf("expected all the fields from GetFields, got %d", len(got))
+	}
+}
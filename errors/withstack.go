@@ -1,6 +1,9 @@
 package errors
 
-import "github.com/StevenACoffman/anotherr/errors/withstack"
+import (
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+	"github.com/StevenACoffman/anotherr/errors/withstack"
+)
 
 // This file mirrors the WithStack functionality from
 // github.com/pkg/errors. We would prefer to reuse the withStack
@@ -24,6 +27,23 @@ func WithStack(err error) error { return withstack.WithStackDepth(err, 1) }
 // See the documentation of WithStack() for more details.
 func WithStackDepth(err error, depth int) error { return withstack.WithStackDepth(err, depth+1) }
 
+// StackCount returns the number of distinct stack traces in the chain
+// of err. More than 1 suggests redundant stack captures.
+// See errbase.StackCount() for details.
+func StackCount(err error) int { return errbase.StackCount(err) }
+
+// WithoutStacks runs fn, and makes the error constructors called by
+// fn on the same goroutine skip capturing a stack trace. This is
+// meant for bulk operations that create many errors.
+// See errbase.WithoutStacks() for the caveats.
+func WithoutStacks(fn func()) { errbase.WithoutStacks(fn) }
+
+// SetElideInternalFrames controls whether the stack traces captured
+// by the error constructors drop their leading frames that belong to
+// this module or to the Go runtime. Enabled by default.
+// See errbase.SetElideInternalFrames() for details.
+func SetElideInternalFrames(enabled bool) { errbase.SetElideInternalFrames(enabled) }
+
 // ReportableStackTrace aliases the type of the same name in the sentry
 // package. This is used by SendReport().
 type ReportableStackTrace = withstack.ReportableStackTrace
@@ -48,8 +68,16 @@ func GetOneLineSource(err error) (file string, line int, fn string, ok bool) {
 // - any other error that implements a StackTrace() method
 //   returning a StackTrace from github.com/pkg/errors.
 //
+// The stack trace is the complete one recorded in err, independently
+// of how it is printed with %+v: the frames shared with the stack
+// traces of its causes, which the formatter elides, are included, and
+// the file paths are absolute whatever SetStackPathMode() says.
+//
 // Note: Sentry wants the oldest call frame first, so
 // the entries are reversed in the result.
+//
+// If err has several stack traces, that of the innermost layer that
+// has one is used.
 func GetReportableStackTrace(err error) *ReportableStackTrace {
 	return withstack.GetReportableStackTrace(err)
 }
@@ -15,16 +15,25 @@ import (
 // The filename is simplified to remove the path prefix.
 // This is used e.g. to populate the "source" field in
 // PostgreSQL errors.
+//
+// The layers are visited as with errbase.Walk(), and the innermost
+// stack trace is the last one found: in a multi-error, that of the
+// last branch that has one.
 func GetOneLineSource(err error) (file string, line int, fn string, ok bool) {
-	// We want the innermost entry: start by recursing.
-	if c := errbase.UnwrapOnce(err); c != nil {
-		if file, line, fn, ok = GetOneLineSource(c); ok {
-			return
+	errbase.Walk(err, func(c error) bool {
+		if f, l, n, found := getOneLineSourceOfLayer(c); found {
+			file, line, fn, ok = f, l, n, true
 		}
-	}
-	// If we reach this point, we haven't found anything in the cause so
-	// far. Look at the current level.
 
+		return true
+	})
+
+	return file, line, fn, ok
+}
+
+// getOneLineSourceOfLayer is like GetOneLineSource but only looks at
+// the stack trace of err itself, not at those of its causes.
+func getOneLineSourceOfLayer(err error) (file string, line int, fn string, ok bool) {
 	// If we have a stack trace in the style of github.com/pkg/errors
 	// (either from there or our own withStack), use it.
 	if st, ok := err.(errbase.StackTraceProvider); ok {
@@ -40,9 +40,34 @@ type ReportableStackTrace = sentry.Stacktrace
 // - any other error that implements a StackTrace() method
 //   returning a StackTrace from github.com/pkg/errors.
 //
+// The stack trace is the complete one recorded in err, independently
+// of how it is printed with %+v: the frames shared with the stack
+// traces of its causes, which the formatter elides, are included, and
+// the file paths are absolute whatever SetStackPathMode() says.
+//
+// If err has several stack traces, that of the innermost layer that
+// has one is used, the layers being visited as with errbase.Walk(): in
+// a multi-error, the last branch that has a stack trace wins.
+//
 // Note: Sentry wants the oldest call frame first, so
 // the entries are reversed in the result.
 func GetReportableStackTrace(err error) *ReportableStackTrace {
+	var res *ReportableStackTrace
+	errbase.Walk(err, func(c error) bool {
+		if st := getReportableStackTraceOfLayer(c); st != nil {
+			res = st
+		}
+
+		return true
+	})
+
+	return res
+}
+
+// getReportableStackTraceOfLayer is like GetReportableStackTrace but
+// only looks at the stack trace of err itself, not at those of its
+// causes.
+func getReportableStackTraceOfLayer(err error) *ReportableStackTrace {
 	// If we have a stack trace in the style of github.com/pkg/errors
 	// (either from there or our own withStack), use it.
 	if st, ok := err.(errbase.StackTraceProvider); ok {
@@ -51,11 +51,20 @@ func (s *stack) StackTrace() errbase.StackTrace {
 
 // callers mirrors the code in github.com/pkg/errors,
 // but makes the depth customizable.
+//
+// Only the program counters are recorded here; resolving them to
+// function names, files and lines is deferred until the stack is
+// formatted, so errors that are never printed don't pay for it. The
+// walk itself cannot be deferred: by the time the error is formatted,
+// the frames that created it are gone.
 func callers(depth int) *stack {
+	if errbase.StacksSuppressed() {
+		return &stack{}
+	}
 	const numFrames = 32
 	var pcs [numFrames]uintptr
 	n := runtime.Callers(2+depth, pcs[:])
-	var st stack = pcs[0:n]
+	var st stack = errbase.TrimInternalFrames(pcs[0:n])
 
 	return &st
 }
@@ -84,3 +84,9 @@ func (w *withStack) SafeFormatError(p errbase.Printer) error {
 func (w *withStack) SafeDetails() []string {
 	return []string{fmt.Sprintf("%+v", w.StackTrace())}
 }
+
+func init() {
+	errbase.RegisterRewrapper((*withStack)(nil), func(err, newCause error) error {
+		return &withStack{cause: newCause, stack: err.(*withStack).stack}
+	})
+}
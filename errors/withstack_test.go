@@ -0,0 +1,199 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/anotherr/errors/errbase"
+)
+
+// line returns the line number of its caller.
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+
+	return l
+}
+
+func TestStackCountThroughMultiError(t *testing.T) {
+	// The stack traces of New() and WithStack() on the same line are
+	// identical, so each branch counts for 1.
+	a := WithStack(New("a"))
+	b := WithStack(New("b"))
+	err := WithHint(multiError{a, b}, "h")
+	if n := StackCount(err); n != 2 {
+		t.Errorf("expected 2 stack traces, got %d", n)
+	}
+}
+
+func TestGetOneLineSourceThroughMultiError(t *testing.T) {
+	inner, innerLine := WithStack(stderrors.New("a")), line()
+	err := WithStack(WithHint(multiError{inner, stderrors.New("b")}, "h"))
+
+	_, l, fn, ok := GetOneLineSource(err)
+	if !ok {
+		t.Fatal("no source found")
+	}
+	if l != innerLine || fn != "TestGetOneLineSourceThroughMultiError" {
+		t.Errorf("expected the innermost stack trace at line %d, got %s at line %d", innerLine, fn, l)
+	}
+}
+
+func TestGetReportableStackTraceThroughMultiError(t *testing.T) {
+	err := WithHint(multiError{New("a"), New("b")}, "h")
+
+	st := GetReportableStackTrace(err)
+	if st == nil || len(st.Frames) == 0 {
+		t.Fatal("expected a stack trace from the branches of the multi-error")
+	}
+	last := st.Frames[len(st.Frames)-1]
+	if last.Function != "TestGetReportableStackTraceThroughMultiError" {
+		t.Errorf("expected the test as innermost frame, got %s", last.Function)
+	}
+}
+
+func TestWithoutStacks(t *testing.T) {
+	var inside []error
+	var otherGoroutine error
+	WithoutStacks(func() {
+		inside = []error{
+			New("a"),
+			Wrap(stderrors.New("b"), "wrapped"),
+			WithStack(stderrors.New("c")),
+			NotFound("d"),
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			otherGoroutine = New("e")
+		}()
+		<-done
+	})
+
+	for _, err := range inside {
+		if _, _, _, ok := GetOneLineSource(err); ok {
+			t.Errorf("expected no stack trace in %v", err)
+		}
+	}
+	for _, err := range []error{otherGoroutine, New("f")} {
+		if _, _, _, ok := GetOneLineSource(err); !ok {
+			t.Errorf("expected a stack trace in %v", err)
+		}
+	}
+}
+
+func BenchmarkWithoutStacks(b *testing.B) {
+	b.Run("stacks", func(b *testing.B) {
+		atDepth(30, func() {
+			for i := 0; i < b.N; i++ {
+				_ = New("x")
+			}
+		})
+	})
+	b.Run("no stacks", func(b *testing.B) {
+		atDepth(30, func() {
+			WithoutStacks(func() {
+				for i := 0; i < b.N; i++ {
+					_ = New("x")
+				}
+			})
+		})
+	})
+}
+
+func TestTopFrameIsCaller(t *testing.T) {
+	root := stderrors.New("root")
+
+	for i, err := range []error{
+		New("boom"),
+		Newf("boom %d", 1),
+		Errorf("boom %d", 1),
+		NewLazyf("boom %d", 1),
+		AssertionFailedf("boom %d", 1),
+		Wrap(root, "loading"),
+		Wrapf(root, "loading %d", 1),
+		WithStack(root),
+		WrapWithFields(root, Fields{"user": 42}),
+		NotFound("no user"),
+		Internal(root),
+		KhanWrap(root, "user", 42),
+		InternalWithFields(root, "loading", Fields{"user": 42}),
+		WrapEach([]error{root}, "loading")[0],
+	} {
+		var n int
+		errbase.Walk(err, func(c error) bool {
+			p, ok := c.(errbase.StackTraceProvider)
+			if !ok || len(p.StackTrace()) == 0 {
+				return true
+			}
+			n++
+			fn := runtime.FuncForPC(uintptr(p.StackTrace()[0]) - 1)
+			if name := fn.Name(); !strings.HasSuffix(name, ".TestTopFrameIsCaller") {
+				t.Errorf("%d (%v): expected the test as top frame of %T, got %s", i, err, c, name)
+			}
+
+			return true
+		})
+		if n == 0 {
+			t.Errorf("%d (%v): expected a stack trace", i, err)
+		}
+	}
+}
+
+func TestStackCount(t *testing.T) {
+	if n := StackCount(New("boom")); n != 1 {
+		t.Errorf("expected 1 stack trace for New, got %d", n)
+	}
+	if n := StackCount(stderrors.New("boom")); n != 0 {
+		t.Errorf("expected no stack trace, got %d", n)
+	}
+
+	// Each WithStack on its own line captures a distinct stack trace.
+	err := WithStack(stderrors.New("boom"))
+	err = WithStack(err)
+	err = WithStack(err)
+	if n := StackCount(err); n != 3 {
+		t.Errorf("expected 3 distinct stack traces, got %d", n)
+	}
+
+	// Identical stack traces, captured on the same line, count once.
+	err = WithStack(WithStack(WithStack(stderrors.New("boom"))))
+	if n := StackCount(err); n != 1 {
+		t.Errorf("expected 1 distinct stack trace, got %d", n)
+	}
+}
+
+// newAtDepth1 returns an error whose stack trace has one more frame
+// than that of its caller.
+func newAtDepth1() error { return New("boom") }
+
+func TestGetReportableStackTraceWithSharedSuffix(t *testing.T) {
+	inner := newAtDepth1()
+	err := Wrap(inner, "loading")
+
+	// The stack trace of the wrapper is elided when formatting, as it
+	// is a suffix of the inner one.
+	if verbose := fmt.Sprintf("%+v", err); !strings.Contains(verbose, "[...repeated from below...]") {
+		t.Fatalf("expected an elided stack trace, got:\n%s", verbose)
+	}
+
+	st := GetReportableStackTrace(err)
+	if st == nil {
+		t.Fatal("expected a stack trace")
+	}
+	raw := inner.(errbase.StackTraceProvider).StackTrace()
+	if len(st.Frames) != len(raw) {
+		t.Errorf("expected all the %d frames, got %d", len(raw), len(st.Frames))
+	}
+	var functions []string
+	for _, f := range st.Frames {
+		functions = append(functions, f.Function)
+	}
+	want := []string{"tRunner", "TestGetReportableStackTraceWithSharedSuffix", "newAtDepth1"}
+	if got := functions[len(functions)-len(want):]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the frames %q, got %q", want, functions)
+	}
+}